@@ -0,0 +1,14 @@
+// Command pathparams runs the pathparams analyzer standalone or as vet tool:
+//
+//	go vet -vettool=$(which pathparams) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/kxnes/mixer/analysis/pathparams"
+)
+
+func main() {
+	singlechecker.Main(pathparams.Analyzer)
+}
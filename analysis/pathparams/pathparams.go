@@ -0,0 +1,317 @@
+// Package pathparams defines the analyzer which checks the usage of mixer.PathParams
+// in the handlers against the patterns they are registered with.
+//
+// For every registration like
+//
+//	mux.GetFunc("/catalog/:int", h)
+//
+// with the constant pattern the handler body (func literal, function or method of the package)
+// is inspected for PathParams indexing `params[i]`, type assertions `params[i].(T)` and
+// accessors like `params.String(i)`. The index out of the pattern params count and
+// the type which the converter of the param never produces are reported.
+// The constant pattern which mixer rejects is reported instead of its handler.
+package pathparams
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const mixerPath = "github.com/kxnes/mixer"
+
+// Analyzer checks the usage of mixer.PathParams against the registered patterns.
+var Analyzer = &analysis.Analyzer{
+	Name:     "pathparams",
+	Doc:      "check mixer.PathParams usage against the registered patterns",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// registrations maps the registration methods of mixer.ServeMux to the pattern argument index.
+var registrations = map[string]int{
+	"Handle": 1, "HandleFunc": 1, "TryHandle": 1,
+	"Get": 0, "Head": 0, "Post": 0, "Put": 0, "Patch": 0, "Delete": 0, "Connect": 0, "Options": 0, "Trace": 0,
+	"GetFunc": 0, "HeadFunc": 0, "PostFunc": 0, "PutFunc": 0, "PatchFunc": 0,
+	"DeleteFunc": 0, "ConnectFunc": 0, "OptionsFunc": 0, "TraceFunc": 0,
+}
+
+// converters maps the converter of the path param to the kind of its values.
+var converters = map[string]string{"": "string", "str": "string", "int": "int"}
+
+// accessors maps the PathParams methods to the types of values they accept.
+var accessors = map[string][]string{
+	"Value":        nil,
+	"Int":          {"int", "int64"},
+	"Int64":        {"int", "int64"},
+	"String":       {"string"},
+	"LookupInt":    {"int", "int64"},
+	"LookupInt64":  {"int", "int64"},
+	"LookupString": {"string"},
+	"MustInt":      {"int", "int64"},
+	"MustInt64":    {"int", "int64"},
+	"MustString":   {"string"},
+}
+
+// checker represents the state of the pass.
+type checker struct {
+	pass     *analysis.Pass
+	funcs    map[*types.Func]*ast.FuncDecl
+	reported map[string]bool
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	c := &checker{pass: pass, funcs: make(map[*types.Func]*ast.FuncDecl), reported: make(map[string]bool)}
+
+	ins.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(n ast.Node) {
+		fd := n.(*ast.FuncDecl)
+		if fn, ok := pass.TypesInfo.Defs[fd.Name].(*types.Func); ok && fd.Body != nil {
+			c.funcs[fn] = fd
+		}
+	})
+
+	ins.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		c.registration(n.(*ast.CallExpr))
+	})
+
+	return nil, nil
+}
+
+// registration checks the handler of call if it is the registration with constant pattern.
+func (c *checker) registration(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+
+	i, ok := registrations[sel.Sel.Name]
+	if !ok || len(call.Args) < i+2 || !isMixer(c.pass.TypesInfo.TypeOf(sel.X), "ServeMux") {
+		return
+	}
+
+	tv := c.pass.TypesInfo.Types[call.Args[i]]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return
+	}
+
+	pattern := constant.StringVal(tv.Value)
+
+	kinds, err := params(pattern)
+	if err != nil {
+		c.report(call.Args[i].Pos(), "invalid pattern %q: %s", pattern, err)
+		return
+	}
+
+	if body := c.body(call.Args[i+1]); body != nil {
+		c.check(body, pattern, kinds)
+	}
+}
+
+// body returns the body of the handler expression or nil if it is unknown.
+// The func literals, functions and method values of the package and conversions
+// to http.HandlerFunc are resolved.
+func (c *checker) body(expr ast.Expr) *ast.BlockStmt {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.FuncLit:
+		return e.Body
+	case *ast.Ident:
+		if fn, ok := c.pass.TypesInfo.Uses[e].(*types.Func); ok && c.funcs[fn] != nil {
+			return c.funcs[fn].Body
+		}
+	case *ast.SelectorExpr:
+		if fn, ok := c.pass.TypesInfo.Uses[e.Sel].(*types.Func); ok && c.funcs[fn] != nil {
+			return c.funcs[fn].Body
+		}
+	case *ast.CallExpr:
+		if tv := c.pass.TypesInfo.Types[e.Fun]; tv.IsType() && len(e.Args) == 1 {
+			return c.body(e.Args[0])
+		}
+	}
+
+	return nil
+}
+
+// check reports the PathParams usages in body which disagree with the pattern params kinds.
+func (c *checker) check(body *ast.BlockStmt, pattern string, kinds []string) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.TypeAssertExpr:
+			if ix, ok := ast.Unparen(e.X).(*ast.IndexExpr); ok && e.Type != nil {
+				if i, ok := c.index(ix.X, ix.Index); ok {
+					c.use(ix.Index, pattern, kinds, i, []string{c.pass.TypesInfo.TypeOf(e.Type).String()})
+				}
+
+				return true
+			}
+		case *ast.IndexExpr:
+			if i, ok := c.index(e.X, e.Index); ok {
+				c.use(e.Index, pattern, kinds, i, nil)
+			}
+		case *ast.CallExpr:
+			sel, ok := e.Fun.(*ast.SelectorExpr)
+			if !ok || len(e.Args) != 1 {
+				break
+			}
+
+			accepted, ok := accessors[sel.Sel.Name]
+			if !ok {
+				break
+			}
+
+			if i, ok := c.index(sel.X, e.Args[0]); ok {
+				c.use(e.Args[0], pattern, kinds, i, accepted)
+			}
+		}
+
+		return true
+	})
+}
+
+// index returns the constant index if x is mixer.PathParams.
+func (c *checker) index(x, index ast.Expr) (int, bool) {
+	if !isMixer(c.pass.TypesInfo.TypeOf(x), "PathParams") {
+		return 0, false
+	}
+
+	tv := c.pass.TypesInfo.Types[index]
+	if tv.Value == nil {
+		return 0, false
+	}
+
+	i, ok := constant.Int64Val(constant.ToInt(tv.Value))
+
+	return int(i), ok
+}
+
+// use reports the usage of path param i at pos if it is out of kinds or
+// its kind is not in accepted (nil accepts any kind, empty kind is unknown).
+func (c *checker) use(at ast.Expr, pattern string, kinds []string, i int, accepted []string) {
+	switch {
+	case i < 0 || i >= len(kinds):
+		c.report(at.Pos(), "path param index %d out of range: pattern %q has %d path params", i, pattern, len(kinds))
+	case accepted != nil && kinds[i] != "" && !contains(accepted, kinds[i]):
+		c.report(at.Pos(), "path param %d of pattern %q is %s, not %s",
+			i, pattern, kinds[i], strings.Join(accepted, " or "))
+	}
+}
+
+// report reports the diagnostic once per position and message.
+func (c *checker) report(pos token.Pos, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	key := fmt.Sprint(pos) + msg
+
+	if !c.reported[key] {
+		c.reported[key] = true
+		c.pass.Reportf(pos, "%s", msg)
+	}
+}
+
+// params returns the kinds of the pattern path params in order of PathParams:
+// the host params first and then the path ones. The pattern is validated like
+// by mixer.ServeMux.ParsePattern, the error has the byte offset of the problem.
+func params(pattern string) ([]string, error) {
+	host, path := pattern, ""
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		host, path = pattern[:i], pattern[i:]
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("missing path at offset %d", len(host))
+	}
+
+	var kinds []string
+
+	// param appends the kind of the path param part at offset if it is one.
+	param := func(part string, off int) error {
+		if !strings.HasPrefix(part, ":") {
+			return nil
+		}
+
+		kind, ok := converters[part[1:]]
+		if !ok {
+			return fmt.Errorf("unknown converter %q at offset %d", part[1:], off)
+		}
+
+		kinds = append(kinds, kind)
+
+		return nil
+	}
+
+	if host != "" {
+		off := 0
+
+		for _, label := range strings.Split(host, ".") {
+			switch {
+			case label == "":
+				return nil, fmt.Errorf("empty host label at offset %d", off)
+			case label == "*":
+				return nil, fmt.Errorf("catch-all in host at offset %d", off)
+			}
+
+			if err := param(label, off); err != nil {
+				return nil, err
+			}
+
+			off += len(label) + 1
+		}
+	}
+
+	off := len(host) + 1
+	parts := strings.Split(path[1:], "/")
+
+	for i, part := range parts {
+		last := i == len(parts)-1
+
+		switch {
+		case part == "" && !last:
+			return nil, fmt.Errorf("empty path part at offset %d", off)
+		case part == "*" && !last:
+			return nil, fmt.Errorf("catch-all is not the last path part at offset %d", off)
+		case part == "*":
+			kinds = append(kinds, "string") // catch-all is the rest of the path
+		default:
+			if err := param(part, off); err != nil {
+				return nil, err
+			}
+		}
+
+		off += len(part) + 1
+	}
+
+	return kinds, nil
+}
+
+// isMixer reports whether t is the named type (or the pointer to it) of mixer package.
+func isMixer(t types.Type, name string) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+
+	n, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+
+	obj := n.Obj()
+
+	return obj.Pkg() != nil && obj.Pkg().Path() == mixerPath && obj.Name() == name
+}
+
+// contains reports whether values contain value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,13 @@
+package pathparams_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/kxnes/mixer/analysis/pathparams"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), pathparams.Analyzer, "a")
+}
@@ -0,0 +1,55 @@
+package a
+
+import (
+	"net/http"
+
+	"github.com/kxnes/mixer"
+)
+
+const catalog = "/catalog/:int"
+
+func Register(mux *mixer.ServeMux) {
+	mux.GetFunc(catalog, Retrieve)
+	mux.GetFunc("/users/:int/:str", func(w http.ResponseWriter, r *http.Request) {
+		params := mixer.GetPathParams(r)
+		_ = params[0].(int)
+		_ = params[1].(int)      // want `path param 1 of pattern "/users/:int/:str" is string, not int`
+		_ = params[2]            // want `path param index 2 out of range: pattern "/users/:int/:str" has 2 path params`
+		_ = params.MustString(0) // want `path param 0 of pattern "/users/:int/:str" is int, not string`
+		_, _ = params.LookupInt64(0)
+	})
+	mux.Get(":str.example.org/:int", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = mixer.GetPathParams(r).MustString(0)
+		_, _ = mixer.GetPathParams(r).Int(1)
+	}))
+	mux.Handle(http.MethodPost, "/files/:", http.HandlerFunc(Upload))
+	mux.GetFunc("/static/:int/*", func(w http.ResponseWriter, r *http.Request) {
+		_ = mixer.GetPathParams(r).MustString(1)
+		_ = mixer.GetPathParams(r)[1].(int) // want `path param 1 of pattern "/static/:int/\*" is string, not int`
+	})
+
+	var users Users
+	mux.GetFunc("/users/:int", users.Retrieve)
+
+	mux.GetFunc("/a/*/b", Retrieve)    // want `invalid pattern "/a/\*/b": catch-all is not the last path part at offset 3`
+	mux.GetFunc("/c//d", Retrieve)     // want `invalid pattern "/c//d": empty path part at offset 3`
+	mux.GetFunc("/e/:uuid", Retrieve)  // want `invalid pattern "/e/:uuid": unknown converter "uuid" at offset 3`
+	mux.GetFunc("example.org", Upload) // want `invalid pattern "example.org": missing path at offset 11`
+
+	pattern := "/" + catalog
+	mux.GetFunc(pattern, Retrieve) // not constant, skipped
+}
+
+func Retrieve(w http.ResponseWriter, r *http.Request) {
+	_ = mixer.GetPathParams(r)[0].(string) // want `path param 0 of pattern "/catalog/:int" is int, not string`
+}
+
+func Upload(w http.ResponseWriter, r *http.Request) {
+	_, _ = mixer.GetPathParams(r).Int(0) // want `path param 0 of pattern "/files/:" is string, not int or int64`
+}
+
+type Users struct{}
+
+func (Users) Retrieve(w http.ResponseWriter, r *http.Request) {
+	_ = mixer.GetPathParams(r)[0].(string) // want `path param 0 of pattern "/users/:int" is int, not string`
+}
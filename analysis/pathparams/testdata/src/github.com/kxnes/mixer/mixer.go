@@ -0,0 +1,19 @@
+package mixer
+
+import "net/http"
+
+type (
+	PathParams map[int]interface{}
+	ServeMux   struct{}
+)
+
+func GetPathParams(r *http.Request) PathParams { return nil }
+
+func (p PathParams) Int(i int) (int, error)          { return 0, nil }
+func (p PathParams) MustString(i int) string         { return "" }
+func (p PathParams) LookupInt64(i int) (int64, bool) { return 0, false }
+
+func (mux *ServeMux) Handle(method, pattern string, handler http.Handler) {}
+func (mux *ServeMux) Get(pattern string, handler http.Handler)            {}
+func (mux *ServeMux) GetFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+}
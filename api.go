@@ -5,10 +5,15 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"sync"
+	"time"
 )
 
 type (
 	// PathParams represents map of path params that will store by index.
+	// The index is the position of the param in the pattern, the host params first.
+	// The pattern syntax does not name the params (e.g. `/a/:int`), so the accessors
+	// like Int and MustString take the index and there are no named variants.
 	PathParams map[int]interface{}
 
 	// ServeMuxError decorates all possible external errors to one kind.
@@ -18,10 +23,62 @@ type (
 		err     error
 	}
 
+	// Match represents the result of matching the request by ServeMux.
+	Match struct {
+		// Handler is the handler to use for the request.
+		Handler http.Handler
+
+		// Pattern is the pattern of the matched route as it was registered.
+		Pattern string
+
+		// Name is the name of the matched route (see WithName).
+		Name string
+
+		// Params is the path params of the request.
+		Params PathParams
+
+		// Methods is the sorted methods registered for the matched path.
+		Methods []string
+
+		route *route
+		node  *node
+	}
+
+	// Route represents the registered route.
+	Route struct {
+		Method  string
+		Pattern string
+		Name    string
+
+		// Handler is the handler composed with the route middleware.
+		Handler http.Handler
+	}
+
+	// Middleware represents the function which decorates the handler.
+	Middleware func(http.Handler) http.Handler
+
+	// RouteOption configures the route on registration.
+	RouteOption func(*route)
+
 	// ServeMux is an HTTP request multiplexer.
 	ServeMux struct {
+		// mu guards tree and hosts, the published trees are never modified
+		mu         sync.RWMutex
 		tree       *tree
+		hosts      *tree
 		converters map[string]*convert
+		middleware []Middleware
+		normalize  func(string) string
+		buckets    []float64
+		cors       *CORS
+		spas       []SPA
+
+		errorHandler func(http.ResponseWriter, *http.Request, error)
+
+		raw      bool
+		fold     bool
+		redirect bool
+		recovery bool
 	}
 )
 
@@ -29,6 +86,9 @@ var (
 	// PathParamsCtxKey is a context key for using in context.Value.
 	PathParamsCtxKey = &contextKey{"path-params"}
 
+	// RouteCtxKey is a context key of the matched route for using in context.Value.
+	RouteCtxKey = &contextKey{"route"}
+
 	// ErrMethod is the error if try set handler for wrong method inside ServeMux.
 	ErrMethod = errors.New("invalid method")
 
@@ -62,6 +122,27 @@ func (e *ServeMuxError) Unwrap() error {
 	return e.err
 }
 
+// Method returns the method of the failed registration or request.
+func (e *ServeMuxError) Method() string {
+	return e.method
+}
+
+// Pattern returns the pattern of the failed registration or the path of the request.
+func (e *ServeMuxError) Pattern() string {
+	return e.pattern
+}
+
+// Conflict returns the details of ErrMultiplePathParam or ErrDuplicate error
+// of the registration or nil otherwise.
+func (e *ServeMuxError) Conflict() *Conflict {
+	var c *Conflict
+	if errors.As(e.err, &c) {
+		return c
+	}
+
+	return nil
+}
+
 // GetPathParams returns the path params registered in r.Context() or nil otherwise.
 func GetPathParams(r *http.Request) PathParams {
 	params, ok := r.Context().Value(PathParamsCtxKey).(PathParams)
@@ -73,51 +154,128 @@ func GetPathParams(r *http.Request) PathParams {
 	return params
 }
 
-// Handler returns the handler to use for the given request.
-func (mux *ServeMux) Handler(r *http.Request) (http.Handler, error) {
-	url := r.URL.EscapedPath()
-	parts, _ := splitURL(url)
+// GetRoute returns the matched route registered in r.Context() or nil otherwise.
+// It can be used by the middleware for the low-cardinality labels like `/catalog/:int`.
+func GetRoute(r *http.Request) *Route {
+	rt, ok := r.Context().Value(RouteCtxKey).(*Route)
 
-	i := 0
-	node := mux.tree.root
+	if !ok {
+		return nil
+	}
+
+	return rt
+}
+
+// Match returns the result of matching the request without any side effects.
+// The path parts are percent-decoded before matching and converting to path params,
+// but the escaped `/` inside part stays a part of the value (see WithRawPathParams).
+// The host patterns are checked first and if no one matches or the path is not found
+// in the routes of the host the request will be searched in the tree of the patterns without host.
+// If the node is found but has no handler for the request method
+// the error is returned with Match containing params and allowed methods.
+func (mux *ServeMux) Match(r *http.Request) (Match, error) {
+	path := r.URL.EscapedPath()
+	parts, _ := splitURL(path)
 	params := make(PathParams)
+	paths, hosts := mux.trees()
 
-	for _, part := range parts {
-		child, ok := node.Children[part]
-		if ok {
-			node = child
-			continue
-		}
+	root := searchHost(paths, hosts, r, params)
 
-		child, ok = node.Children[typeToken]
-		if !ok {
-			return nil, notFoundError(r.Method, url)
-		}
+	node := root.search(parts, params, mux.segment)
+	if (node == nil || len(node.Methods) == 0) && root != paths.root {
+		// the path is not found in the routes of host, the host params are dropped
+		params = make(PathParams)
+		node = paths.root.search(parts, params, mux.segment)
+	}
 
-		val, err := (*child.conv)(part)
-		if err != nil {
-			return nil, notFoundError(r.Method, url)
-		}
+	if node == nil || len(node.Methods) == 0 {
+		return Match{}, notFoundError(r.Method, path)
+	}
 
-		node = child
-		params[i] = val
-		i++
+	if len(params) == 0 {
+		params = nil
 	}
 
-	if node.Methods == nil || node.Methods[r.Method] == nil {
-		return nil, notFoundError(r.Method, url)
+	m := Match{Params: params, Methods: node.methods(), node: node}
+
+	h := node.Methods[r.Method]
+	if h == nil {
+		return m, notFoundError(r.Method, path)
 	}
 
-	if len(params) != 0 {
-		*r = *r.WithContext(context.WithValue(r.Context(), PathParamsCtxKey, params))
+	cs, ok := h.(candidates)
+	if !ok {
+		m.Handler = h
+		return m, nil
 	}
 
-	return node.Methods[r.Method], nil
+	rt, err := cs.match(r)
+	if err != nil {
+		return m, &ServeMuxError{r.Method, path, err}
+	}
+
+	m.Handler, m.Pattern, m.Name, m.route = rt.handler, rt.pattern, rt.name, rt
+
+	if mux.redirect {
+		if target, ok := mux.canonical(rt.pattern, parts); ok {
+			m.Handler = redirectHandler(r, target)
+		}
+	}
+
+	return m, nil
+}
+
+// Handler returns the handler to use for the given request.
+// The path params and the matched route are stored to r.Context()
+// and can be got by GetPathParams and GetRoute.
+// For matching without modifying of the request see Match.
+func (mux *ServeMux) Handler(r *http.Request) (http.Handler, error) {
+	m, err := mux.Match(r)
+	if err != nil {
+		return nil, err
+	}
+
+	*r = *m.request(r)
+
+	return m.Handler, nil
 }
 
 // Handle registers the handler for the given method and pattern.
-// Because it is an initialization moment will be panics in any error.
-func (mux *ServeMux) Handle(method, pattern string, handler http.Handler) {
+// The pattern can be prefixed by host pattern, e.g. `:str.example.com/catalog/`,
+// then the host labels are matched like the path parts and the host params
+// are placed before the path params. The last path part `*` is the catch-all,
+// e.g. `/files/*`, it matches the rest of the path as string path param if no other
+// route matches.
+// Because it is an initialization moment will be panics in any error,
+// for registration in runtime see TryHandle.
+func (mux *ServeMux) Handle(method, pattern string, handler http.Handler, opts ...RouteOption) {
+	if err := mux.TryHandle(method, pattern, handler, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// TryHandle registers the handler for the given method and pattern like Handle
+// but returns the *ServeMuxError instead of panic. The ServeMux stays untouched on failure.
+// It is safe to call concurrently with serving the requests.
+func (mux *ServeMux) TryHandle(method, pattern string, handler http.Handler, opts ...RouteOption) error {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+
+	paths, hosts, err := mux.handle(mux.tree, mux.hosts, method, pattern, handler, opts)
+	if err != nil {
+		return err
+	}
+
+	mux.tree, mux.hosts = paths, hosts
+
+	return nil
+}
+
+// handle registers the route like TryHandle in the copies of the paths and hosts trees
+// and returns the copies. The given trees stay untouched, so they can be read concurrently.
+func (mux *ServeMux) handle(
+	paths, hosts *tree, method, pattern string, handler http.Handler, opts []RouteOption,
+) (*tree, *tree, error) {
 	switch method {
 	case
 		http.MethodGet,
@@ -130,151 +288,295 @@ func (mux *ServeMux) Handle(method, pattern string, handler http.Handler) {
 		http.MethodOptions,
 		http.MethodTrace:
 	default:
-		panic(methodError(method, pattern))
+		return nil, nil, methodError(method, pattern)
 	}
 
 	if handler == nil {
-		panic(handlerError(method, pattern))
+		return nil, nil, handlerError(method, pattern)
 	}
 
-	parts, err := splitURL(pattern)
+	p, err := mux.ParsePattern(pattern)
 	if err != nil {
-		panic(patternError(method, pattern))
+		return nil, nil, &ServeMuxError{method, pattern, err}
 	}
 
-	methods, err := mux.insert(parts)
+	parts, labels := p.parts()
+	mux.keys(parts)
+
+	t := paths
+
+	var host *node // the copy of the last host node which owns the paths of the pattern
+
+	if len(labels) != 0 {
+		cp, last, err := mux.build(hosts, labels)
+		if err == ErrMultiplePathParam {
+			c := mux.segmentConflict(hosts, labels)
+			if c.Segment >= 0 {
+				c.Segment = len(labels) - 1 - c.Segment // labels are reversed
+			}
+
+			return nil, nil, &ServeMuxError{method, pattern, c}
+		}
+
+		if err != nil {
+			return nil, nil, &ServeMuxError{method, pattern, err}
+		}
+
+		if last.sub == nil {
+			last.sub = &tree{root: &node{tid: root}}
+		}
+
+		t, hosts, host = last.sub, cp, last
+	}
+
+	cp, last, err := mux.build(t, parts)
+	if err == ErrMultiplePathParam {
+		c := mux.segmentConflict(t, parts)
+		if c.Segment >= 0 {
+			c.Segment += len(labels)
+		}
+
+		return nil, nil, &ServeMuxError{method, pattern, c}
+	}
+
+	if err != nil {
+		return nil, nil, &ServeMuxError{method, pattern, err}
+	}
+
+	rt := &route{method: method, pattern: pattern, handler: handler}
+	for _, opt := range opts {
+		opt(rt)
+	}
+
+	if rt.cache != nil {
+		rt.handler = rt.cache.handler(rt.handler)
+	}
+
+	rt.handler = chain(rt.handler, rt.middleware)
+	rt.metrics = mux.newMetrics(last.Methods[method], pattern)
+
+	cs, err := addCandidate(last.Methods[method], rt)
 	if err != nil {
-		panic(&ServeMuxError{method, pattern, err})
+		return nil, nil, &ServeMuxError{method, pattern, duplicateConflict(last.Methods[method], method)}
 	}
 
-	if methods[method] != nil {
-		panic(duplicateError(method, pattern))
+	// the copied node shares the methods with the given tree
+	methods := make(map[string]http.Handler, len(last.Methods)+1)
+	for m, h := range last.Methods {
+		methods[m] = h
 	}
 
-	methods[method] = handler
+	methods[method] = cs
+	last.Methods = methods
+
+	if host == nil {
+		return cp, hosts, nil
+	}
+
+	host.sub = cp
+
+	return paths, hosts, nil
 }
 
 // Get registers the GET handler for the given pattern.
-func (mux *ServeMux) Get(pattern string, handler http.Handler) {
-	mux.Handle(http.MethodGet, pattern, handler)
+func (mux *ServeMux) Get(pattern string, handler http.Handler, opts ...RouteOption) {
+	mux.Handle(http.MethodGet, pattern, handler, opts...)
 }
 
 // Head registers the HEAD handler for the given pattern.
-func (mux *ServeMux) Head(pattern string, handler http.Handler) {
-	mux.Handle(http.MethodHead, pattern, handler)
+func (mux *ServeMux) Head(pattern string, handler http.Handler, opts ...RouteOption) {
+	mux.Handle(http.MethodHead, pattern, handler, opts...)
 }
 
 // Post registers the POST handler for the given pattern.
-func (mux *ServeMux) Post(pattern string, handler http.Handler) {
-	mux.Handle(http.MethodPost, pattern, handler)
+func (mux *ServeMux) Post(pattern string, handler http.Handler, opts ...RouteOption) {
+	mux.Handle(http.MethodPost, pattern, handler, opts...)
 }
 
 // Put registers the PUT handler for the given pattern.
-func (mux *ServeMux) Put(pattern string, handler http.Handler) {
-	mux.Handle(http.MethodPut, pattern, handler)
+func (mux *ServeMux) Put(pattern string, handler http.Handler, opts ...RouteOption) {
+	mux.Handle(http.MethodPut, pattern, handler, opts...)
 }
 
 // Patch registers the PATCH handler for the given pattern.
-func (mux *ServeMux) Patch(pattern string, handler http.Handler) {
-	mux.Handle(http.MethodPatch, pattern, handler)
+func (mux *ServeMux) Patch(pattern string, handler http.Handler, opts ...RouteOption) {
+	mux.Handle(http.MethodPatch, pattern, handler, opts...)
 }
 
 // Delete registers the DELETE handler for the given pattern.
-func (mux *ServeMux) Delete(pattern string, handler http.Handler) {
-	mux.Handle(http.MethodDelete, pattern, handler)
+func (mux *ServeMux) Delete(pattern string, handler http.Handler, opts ...RouteOption) {
+	mux.Handle(http.MethodDelete, pattern, handler, opts...)
 }
 
 // Connect registers the CONNECT handler for the given pattern.
-func (mux *ServeMux) Connect(pattern string, handler http.Handler) {
-	mux.Handle(http.MethodConnect, pattern, handler)
+func (mux *ServeMux) Connect(pattern string, handler http.Handler, opts ...RouteOption) {
+	mux.Handle(http.MethodConnect, pattern, handler, opts...)
 }
 
 // Options registers the OPTIONS handler for the given pattern.
-func (mux *ServeMux) Options(pattern string, handler http.Handler) {
-	mux.Handle(http.MethodOptions, pattern, handler)
+func (mux *ServeMux) Options(pattern string, handler http.Handler, opts ...RouteOption) {
+	mux.Handle(http.MethodOptions, pattern, handler, opts...)
 }
 
 // Trace registers the TRACE handler for the given pattern.
-func (mux *ServeMux) Trace(pattern string, handler http.Handler) {
-	mux.Handle(http.MethodTrace, pattern, handler)
+func (mux *ServeMux) Trace(pattern string, handler http.Handler, opts ...RouteOption) {
+	mux.Handle(http.MethodTrace, pattern, handler, opts...)
 }
 
 // HandleFunc registers the handler function for the given method and pattern.
-func (mux *ServeMux) HandleFunc(method, pattern string, handler func(http.ResponseWriter, *http.Request)) {
+func (mux *ServeMux) HandleFunc(
+	method, pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
 	if handler == nil {
 		panic(handlerError(method, pattern))
 	}
 
-	mux.Handle(method, pattern, http.HandlerFunc(handler))
+	mux.Handle(method, pattern, http.HandlerFunc(handler), opts...)
 }
 
 // GetFunc registers the GET handler function for the given pattern.
-func (mux *ServeMux) GetFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	mux.HandleFunc(http.MethodGet, pattern, handler)
+func (mux *ServeMux) GetFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	mux.HandleFunc(http.MethodGet, pattern, handler, opts...)
 }
 
 // HeadFunc registers the HEAD handler function for the given pattern.
-func (mux *ServeMux) HeadFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	mux.HandleFunc(http.MethodHead, pattern, handler)
+func (mux *ServeMux) HeadFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	mux.HandleFunc(http.MethodHead, pattern, handler, opts...)
 }
 
 // PostFunc registers the POST handler function for the given pattern.
-func (mux *ServeMux) PostFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	mux.HandleFunc(http.MethodPost, pattern, handler)
+func (mux *ServeMux) PostFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	mux.HandleFunc(http.MethodPost, pattern, handler, opts...)
 }
 
 // PutFunc registers the PUT handler function for the given pattern.
-func (mux *ServeMux) PutFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	mux.HandleFunc(http.MethodPut, pattern, handler)
+func (mux *ServeMux) PutFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	mux.HandleFunc(http.MethodPut, pattern, handler, opts...)
 }
 
 // PatchFunc registers the PATCH handler function for the given pattern.
-func (mux *ServeMux) PatchFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	mux.HandleFunc(http.MethodPatch, pattern, handler)
+func (mux *ServeMux) PatchFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	mux.HandleFunc(http.MethodPatch, pattern, handler, opts...)
 }
 
 // DeleteFunc registers the DELETE handler function for the given pattern.
-func (mux *ServeMux) DeleteFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	mux.HandleFunc(http.MethodDelete, pattern, handler)
+func (mux *ServeMux) DeleteFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	mux.HandleFunc(http.MethodDelete, pattern, handler, opts...)
 }
 
 // ConnectFunc registers the CONNECT handler function for the given pattern.
-func (mux *ServeMux) ConnectFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	mux.HandleFunc(http.MethodConnect, pattern, handler)
+func (mux *ServeMux) ConnectFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	mux.HandleFunc(http.MethodConnect, pattern, handler, opts...)
 }
 
 // OptionsFunc registers the OPTIONS handler function for the given pattern.
-func (mux *ServeMux) OptionsFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	mux.HandleFunc(http.MethodOptions, pattern, handler)
+func (mux *ServeMux) OptionsFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	mux.HandleFunc(http.MethodOptions, pattern, handler, opts...)
 }
 
 // TraceFunc registers the TRACE handler function for the given pattern.
-func (mux *ServeMux) TraceFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	mux.HandleFunc(http.MethodTrace, pattern, handler)
+func (mux *ServeMux) TraceFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	mux.HandleFunc(http.MethodTrace, pattern, handler, opts...)
+}
+
+// Use appends the router-wide middleware. It decorates the handler of every request
+// including not matched ones and can read the matched route by GetRoute.
+func (mux *ServeMux) Use(mw ...Middleware) {
+	mux.middleware = append(mux.middleware, mw...)
 }
 
 // ServeHTTP implements a Handler's interface.
 func (mux *ServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	h, err := mux.Handler(r)
-	if errors.Is(err, ErrNotFound) {
-		http.NotFound(w, r)
-		return
+	m, err := mux.Match(r)
+	r = m.request(r)
+
+	if m.route != nil && m.route.metrics != nil {
+		sw := &statusWriter{ResponseWriter: w}
+		defer m.route.metrics.observe(sw, time.Now())
+
+		w = sw
 	}
 
-	h.ServeHTTP(w, r)
+	if mux.recovery {
+		if _, ok := w.(*statusWriter); !ok {
+			w = &statusWriter{ResponseWriter: w} // tells serveError whether the response has started
+		}
+
+		defer mux.recover(w, r, m)
+	}
+
+	h := mux.limitHandler(m.Handler, m.route)
+	if err != nil {
+		h = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mux.errorHandler(w, r, err)
+		})
+
+		if index := mux.fallback(r, m, err); index != nil {
+			h = index
+		}
+	}
+
+	chain(mux.corsHandler(h, r, m), mux.middleware).ServeHTTP(w, r)
+}
+
+// request returns the shallow copy of r with the match data stored in context:
+// the path params and the matched route (if it was registered by Handle).
+// If there is nothing to store r is returned.
+func (m Match) request(r *http.Request) *http.Request {
+	if len(m.Params) == 0 && m.Pattern == "" {
+		return r
+	}
+
+	ctx := r.Context()
+
+	if len(m.Params) != 0 {
+		ctx = context.WithValue(ctx, PathParamsCtxKey, m.Params)
+	}
+
+	if m.Pattern != "" {
+		ctx = context.WithValue(ctx, RouteCtxKey, &Route{r.Method, m.Pattern, m.Name, m.Handler})
+	}
+
+	return r.WithContext(ctx)
 }
 
-// New allocates and returns a new ServeMux.
-func New() *ServeMux {
+// New allocates and returns a new ServeMux configured by opts.
+func New(opts ...Option) *ServeMux {
 	sc := convert(strConv)
 	ic := convert(intConv)
 
-	return &ServeMux{
-		tree: &tree{root: &node{tid: root}},
+	mux := &ServeMux{
+		tree:         &tree{root: &node{tid: root}},
+		hosts:        &tree{root: &node{tid: root}},
+		errorHandler: serveError,
 		converters: map[string]*convert{
 			"":    &sc,
 			"str": &sc,
 			"int": &ic,
 		},
 	}
+
+	for _, opt := range opts {
+		opt(mux)
+	}
+
+	return mux
 }
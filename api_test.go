@@ -3,8 +3,11 @@ package mixer
 import (
 	"context"
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -53,6 +56,117 @@ func TestGetPathParams(t *testing.T) {
 	as.Equal(GetPathParams(req), exp, "path params exist")
 }
 
+func TestGetRoute(t *testing.T) {
+	var exp *Route // empty
+
+	ctx := context.Background()
+	req := mustReq(http.NewRequestWithContext(ctx, "", "", nil))
+
+	as := Assert{t}
+	as.Equal(GetRoute(req), exp, "route not set")
+
+	ctx = context.WithValue(ctx, &contextKey{"wrong-key"}, &Route{Method: "GET", Pattern: "/", Name: "root"})
+	req = mustReq(http.NewRequestWithContext(ctx, "", "", nil))
+
+	as.Equal(GetRoute(req), exp, "wrong context key")
+
+	ctx = context.WithValue(ctx, RouteCtxKey, &Route{Method: "GET", Pattern: "/", Name: "root"})
+	req = mustReq(http.NewRequestWithContext(ctx, "", "", nil))
+	exp = &Route{Method: "GET", Pattern: "/", Name: "root"}
+
+	as.Equal(GetRoute(req), exp, "route exist")
+}
+
+func TestServeMuxMatch(t *testing.T) {
+	mux := New()
+	mux.Get("/a/:int/", TestHandler("get"), WithName("get-a"))
+	mux.Put("/a/:int/", TestHandler("put"))
+	mux.Post("/a/:int/", TestHandler("post"), WithMatchers(ContentTypeMatcher("application/json")))
+
+	cases := []struct {
+		name   string
+		method string
+		url    string
+		want   Match
+		err    error
+	}{
+		{
+			name:   "found",
+			method: http.MethodGet,
+			url:    "/a/12/",
+			want: Match{
+				Handler: TestHandler("get"),
+				Pattern: "/a/:int/",
+				Name:    "get-a",
+				Params:  PathParams{0: 12},
+				Methods: []string{http.MethodGet, http.MethodPost, http.MethodPut},
+			},
+		},
+		{
+			name:   "method not registered",
+			method: http.MethodDelete,
+			url:    "/a/12/",
+			want: Match{
+				Params:  PathParams{0: 12},
+				Methods: []string{http.MethodGet, http.MethodPost, http.MethodPut},
+			},
+			err: notFoundError(http.MethodDelete, "/a/12/"),
+		},
+		{
+			name:   "matchers failed",
+			method: http.MethodPost,
+			url:    "/a/12/",
+			want: Match{
+				Params:  PathParams{0: 12},
+				Methods: []string{http.MethodGet, http.MethodPost, http.MethodPut},
+			},
+			err: &ServeMuxError{http.MethodPost, "/a/12/", ErrUnsupportedMediaType},
+		},
+		{
+			name:   "path not registered",
+			method: http.MethodGet,
+			url:    "/a/",
+			want:   Match{},
+			err:    notFoundError(http.MethodGet, "/a/"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := mustReq(http.NewRequest(c.method, c.url, nil))
+			ctx := req.Context()
+
+			got, err := mux.Match(req)
+			got.route, got.node = nil, nil
+
+			as := Assert{t}
+			as.Equal(got, c.want, "ServeMux.Match() got")
+			as.Equal(err, c.err, "ServeMux.Match() error")
+			as.Equal(req.Context(), ctx, "ServeMux.Match() context")
+		})
+	}
+}
+
+func TestMatchRequest(t *testing.T) {
+	req := mustReq(http.NewRequest(http.MethodGet, "/", nil))
+
+	as := Assert{t}
+	as.PtrEqual(Match{}.request(req), req, "without params")
+
+	got := Match{Params: PathParams{0: 12}}.request(req)
+
+	as.PtrNotEqual(got, req, "with params")
+	as.Equal(GetPathParams(got), PathParams{0: 12}, "with params")
+	as.Equal(GetRoute(got), (*Route)(nil), "with params route")
+	as.Equal(GetPathParams(req), PathParams(nil), "origin request")
+
+	got = Match{Pattern: "/:int", Name: "name"}.request(req)
+
+	as.Equal(GetPathParams(got), PathParams(nil), "with route params")
+	as.Equal(GetRoute(got), &Route{Method: http.MethodGet, Pattern: "/:int", Name: "name"}, "with route")
+	as.Equal(GetRoute(req), (*Route)(nil), "origin request route")
+}
+
 func TestServeMuxHandlerLogicCases(t *testing.T) {
 	mux := New() // for direct compatibility (for not to remap the converters)
 	mux.tree.root = &node{Children: map[string]*node{
@@ -188,7 +302,7 @@ func TestServeMuxHandle(t *testing.T) {
 
 		defer func() {
 			err := recover()
-			if err == nil || errors.Unwrap(err.(error)) != ErrMethod {
+			if err == nil || !errors.Is(err.(error), ErrMethod) {
 				t.Errorf("ServeMux.Handle() got = %v, want = %v", err, ErrMethod)
 			}
 
@@ -203,7 +317,7 @@ func TestServeMuxHandle(t *testing.T) {
 
 		defer func() {
 			err := recover()
-			if err == nil || errors.Unwrap(err.(error)) != ErrHandler {
+			if err == nil || !errors.Is(err.(error), ErrHandler) {
 				t.Errorf("ServeMux.Handle() got = %v, want = %v", err, ErrHandler)
 			}
 
@@ -218,7 +332,7 @@ func TestServeMuxHandle(t *testing.T) {
 
 		defer func() {
 			err := recover()
-			if err == nil || errors.Unwrap(err.(error)) != ErrPattern {
+			if err == nil || !errors.Is(err.(error), ErrPattern) {
 				t.Errorf("ServeMux.Handle() got = %v, want = %v", err, ErrPattern)
 			}
 
@@ -233,8 +347,8 @@ func TestServeMuxHandle(t *testing.T) {
 
 		defer func() {
 			err := recover()
-			if err == nil || errors.Unwrap(err.(error)).Error() != "invalid path param" {
-				t.Errorf("ServeMux.Handle() got = %v, want = %v", err, "invalid path param")
+			if err == nil || !errors.Is(err.(error), ErrPathParam) {
+				t.Errorf("ServeMux.Handle() got = %v, want = %v", err, ErrPathParam)
 			}
 
 			as.EqualIndent(mux.tree, exp.tree, "ServeMux.Handle() tree")
@@ -255,7 +369,7 @@ func TestServeMuxHandle(t *testing.T) {
 
 		defer func() {
 			err := recover()
-			if err == nil || errors.Unwrap(err.(error)) != ErrDuplicate {
+			if err == nil || !errors.Is(err.(error), ErrDuplicate) {
 				t.Errorf("ServeMux.Handle() got = %v, want = %v", err, ErrDuplicate)
 			}
 
@@ -265,7 +379,11 @@ func TestServeMuxHandle(t *testing.T) {
 		mux.Handle(http.MethodGet, "/", TestHandler("another handler"))
 	})
 
-	exp.tree.root.Children["/"].Methods[http.MethodPut] = TestHandler("another handler")
+	exp.tree.root.Children["/"].Methods[http.MethodPut] = candidates{{
+		method:  http.MethodPut,
+		pattern: "/",
+		handler: TestHandler("another handler"),
+	}}
 
 	t.Run("success add handler", func(t *testing.T) {
 		mux.Handle(http.MethodPut, "/", TestHandler("another handler"))
@@ -275,6 +393,523 @@ func TestServeMuxHandle(t *testing.T) {
 	})
 }
 
+func TestServeMuxHandlerUnescape(t *testing.T) {
+	cases := []struct {
+		name   string
+		opts   []Option
+		url    string
+		want   http.Handler
+		params PathParams
+	}{
+		{
+			name:   "decoded param",
+			url:    "/files/hello%20world",
+			want:   TestHandler("files"),
+			params: PathParams{0: "hello world"},
+		},
+		{
+			name:   "escaped slash is data",
+			url:    "/files/a%2Fb",
+			want:   TestHandler("files"),
+			params: PathParams{0: "a/b"},
+		},
+		{
+			name:   "decoded static part",
+			url:    "/caf%C3%A9/12",
+			want:   TestHandler("cafe"),
+			params: PathParams{0: 12},
+		},
+		{
+			name:   "escaped static part",
+			url:    "/docs/hello%20world",
+			want:   TestHandler("docs"),
+			params: nil,
+		},
+		{
+			name:   "escaped colon is static",
+			url:    "/a/%3Aint",
+			want:   TestHandler("colon"),
+			params: nil,
+		},
+		{
+			name:   "escaped colon is not param",
+			url:    "/a/5",
+			want:   nil,
+			params: nil,
+		},
+		{
+			name:   "escaped slash is static",
+			url:    "/b/%2Fx",
+			want:   TestHandler("slash"),
+			params: nil,
+		},
+		{
+			name:   "escaped star is static",
+			url:    "/c/%2A",
+			want:   TestHandler("star"),
+			params: nil,
+		},
+		{
+			name:   "escaped star is not catch-all",
+			url:    "/c/anything/else",
+			want:   nil,
+			params: nil,
+		},
+		{
+			name:   "raw param",
+			opts:   []Option{WithRawPathParams()},
+			url:    "/files/hello%20world",
+			want:   TestHandler("files"),
+			params: PathParams{0: "hello%20world"},
+		},
+		{
+			name:   "raw static part",
+			opts:   []Option{WithRawPathParams()},
+			url:    "/caf%C3%A9/12",
+			want:   nil,
+			params: nil,
+		},
+		{
+			name:   "raw escaped static part",
+			opts:   []Option{WithRawPathParams()},
+			url:    "/docs/hello%20world",
+			want:   TestHandler("docs"),
+			params: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mux := New(c.opts...)
+			mux.Get("/files/:str", TestHandler("files"))
+			mux.Get("/café/:int", TestHandler("cafe"))
+			mux.Get("/docs/hello%20world", TestHandler("docs"))
+			mux.Get("/a/%3Aint", TestHandler("colon"))
+			mux.Get("/b/%2Fx", TestHandler("slash"))
+			mux.Get("/c/%2A", TestHandler("star"))
+
+			req := mustReq(http.NewRequest(http.MethodGet, c.url, nil))
+			got, _ := mux.Handler(req)
+
+			as := Assert{t}
+			as.Equal(got, c.want, "ServeMux.Handler() got")
+			as.Equal(GetPathParams(req), c.params, "ServeMux.Handler() params")
+		})
+	}
+}
+
+func TestServeMuxHandlerCaseInsensitive(t *testing.T) {
+	// decomposes `é` for the test purposes like NFD does
+	nfd := func(s string) string { return strings.ReplaceAll(s, "é", "e\u0301") }
+
+	cases := []struct {
+		name     string
+		opts     []Option
+		url      string
+		want     http.Handler
+		location string
+	}{
+		{
+			name: "exact match by default",
+			url:  "/catalog/12",
+			want: nil,
+		},
+		{
+			name: "folded static part",
+			opts: []Option{WithCaseInsensitive(false)},
+			url:  "/catalog/12",
+			want: TestHandler("catalog"),
+		},
+		{
+			name: "canonical without redirect",
+			opts: []Option{WithCaseInsensitive(true)},
+			url:  "/Catalog/12",
+			want: TestHandler("catalog"),
+		},
+		{
+			name:     "redirect",
+			opts:     []Option{WithCaseInsensitive(true)},
+			url:      "/CATALOG/12?a=b",
+			location: "/Catalog/12?a=b",
+		},
+		{
+			name: "normalized static part",
+			opts: []Option{WithNormalizer(nfd)},
+			url:  "/Caf%C3%A9/",
+			want: TestHandler("cafe"),
+		},
+		{
+			name: "normalized and folded static part",
+			opts: []Option{WithNormalizer(nfd), WithCaseInsensitive(false)},
+			url:  "/CAFE%CC%81/",
+			want: TestHandler("cafe"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mux := New(c.opts...)
+			mux.Get("/Catalog/:int", TestHandler("catalog"))
+			mux.Get("/Café/", TestHandler("cafe"))
+
+			req := mustReq(http.NewRequest(http.MethodGet, c.url, nil))
+			got, _ := mux.Handler(req)
+
+			as := Assert{t}
+
+			if c.location == "" {
+				as.Equal(got, c.want, "ServeMux.Handler() got")
+				return
+			}
+
+			w := httptest.NewRecorder()
+			got.ServeHTTP(w, req)
+
+			as.IntEqual(w.Code, http.StatusMovedPermanently, "ServeMux.Handler() redirect code")
+			as.StrEqual(w.Header().Get("Location"), c.location, "ServeMux.Handler() redirect location")
+		})
+	}
+
+	t.Run("panic on duplicate folded pattern", func(t *testing.T) {
+		mux := New(WithCaseInsensitive(false))
+		mux.Get("/Catalog/", TestHandler("catalog"))
+
+		defer func() {
+			err := recover()
+			if err == nil || !errors.Is(err.(error), ErrDuplicate) {
+				t.Errorf("ServeMux.Handle() got = %v, want = %v", err, ErrDuplicate)
+			}
+		}()
+
+		mux.Get("/catalog/", TestHandler("catalog"))
+	})
+}
+
+func TestServeMuxTryHandleConcurrent(t *testing.T) {
+	mux := New()
+	mux.Get("/a/:int", TestHandler("a"))
+	mux.Get("host.com/b/", TestHandler("b"))
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 100; i++ {
+			must(mux.TryHandle(http.MethodPut, "/a/:int", TestHandler(strconv.Itoa(i)),
+				WithMatchers(QueryMatcher("v", strconv.Itoa(i)))))
+			must(mux.TryHandle(http.MethodGet, "host.com/b/"+strconv.Itoa(i), TestHandler("b")))
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, mustReq(http.NewRequest(http.MethodGet, "http://host.com/a/1", nil)))
+
+		as := Assert{t}
+		as.IntEqual(w.Code, http.StatusOK, "ServeMux.ServeHTTP() code")
+	}
+
+	<-done
+}
+
+func TestServeMuxTryHandle(t *testing.T) {
+	mux := New()
+	mux.Get("/a/", TestHandler("a"))
+	mux.Get("host.com/a/:int", TestHandler("host"))
+
+	cases := []struct {
+		name    string
+		method  string
+		pattern string
+		handler http.Handler
+		want    error
+	}{
+		{
+			name:    "invalid method",
+			method:  "invalid method",
+			pattern: "/b/",
+			handler: TestHandler("b"),
+			want:    methodError("invalid method", "/b/"),
+		},
+		{
+			name:    "nil handler",
+			method:  http.MethodGet,
+			pattern: "/b/",
+			handler: nil,
+			want:    handlerError(http.MethodGet, "/b/"),
+		},
+		{
+			name:    "invalid pattern",
+			method:  http.MethodGet,
+			pattern: "/b//",
+			handler: TestHandler("b"),
+			want:    &ServeMuxError{http.MethodGet, "/b//", &PatternError{3, "empty path part", "", ErrPattern}},
+		},
+		{
+			name:    "relative pattern",
+			method:  http.MethodGet,
+			pattern: "catalog/",
+			handler: TestHandler("b"),
+			want: &ServeMuxError{
+				http.MethodGet, "catalog/", &PatternError{0, "missing path", "the path must start with '/'", ErrPattern},
+			},
+		},
+		{
+			name:    "invalid host",
+			method:  http.MethodGet,
+			pattern: "host..com/b/",
+			handler: TestHandler("b"),
+			want:    &ServeMuxError{http.MethodGet, "host..com/b/", &PatternError{5, "empty host label", "", ErrPattern}},
+		},
+		{
+			name:    "invalid path param",
+			method:  http.MethodGet,
+			pattern: "/b/:mem",
+			handler: TestHandler("b"),
+			want:    &ServeMuxError{http.MethodGet, "/b/:mem", &PatternError{3, "unknown converter 'mem'", "", ErrPathParam}},
+		},
+		{
+			name:    "multiple path param",
+			method:  http.MethodGet,
+			pattern: "host.com/a/:str",
+			handler: TestHandler("b"),
+			want: &ServeMuxError{http.MethodGet, "host.com/a/:str", &Conflict{
+				Method:       http.MethodGet,
+				Pattern:      "host.com/a/:int",
+				Segment:      3,
+				Kind:         ParamNode,
+				ExistingKind: ParamNode,
+				err:          ErrMultiplePathParam,
+			}},
+		},
+		{
+			name:    "duplicate",
+			method:  http.MethodGet,
+			pattern: "/a/",
+			handler: TestHandler("b"),
+			want: &ServeMuxError{http.MethodGet, "/a/", &Conflict{
+				Method:  http.MethodGet,
+				Pattern: "/a/",
+				Segment: -1,
+				err:     ErrDuplicate,
+			}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			paths, hosts := mux.tree.String(), mux.hosts.String()
+
+			err := mux.TryHandle(c.method, c.pattern, c.handler)
+
+			as := Assert{t}
+			as.Equal(err, c.want, "ServeMux.TryHandle() error")
+			as.StrEqual(mux.tree.String(), paths, "ServeMux.TryHandle() tree")
+			as.StrEqual(mux.hosts.String(), hosts, "ServeMux.TryHandle() hosts")
+		})
+	}
+
+	t.Run("success", func(t *testing.T) {
+		err := mux.TryHandle(http.MethodGet, "/b/", TestHandler("b"))
+
+		as := Assert{t}
+		as.Equal(err, nil, "ServeMux.TryHandle() error")
+	})
+}
+
+func TestServeMuxHandleHost(t *testing.T) {
+	mux := New()
+	mux.Handle(http.MethodGet, ":str.:int.Example.com/a/:int", TestHandler("tenant"))
+	mux.Handle(http.MethodGet, "api.example.org/a/", TestHandler("api"))
+	mux.Handle(http.MethodGet, "/a/", TestHandler("default"))
+	mux.Handle(http.MethodGet, "/health", TestHandler("health"))
+
+	cases := []struct {
+		name   string
+		host   string
+		path   string
+		want   http.Handler
+		params PathParams
+		err    error
+	}{
+		{
+			name:   "typed host",
+			host:   "foo.1.example.com",
+			path:   "/a/12",
+			want:   TestHandler("tenant"),
+			params: PathParams{0: "foo", 1: 1, 2: 12},
+		},
+		{
+			name:   "typed host with port and case",
+			host:   "Foo.2.EXAMPLE.com:8080",
+			path:   "/a/12",
+			want:   TestHandler("tenant"),
+			params: PathParams{0: "foo", 1: 2, 2: 12},
+		},
+		{
+			name: "typed host falls back to default tree",
+			host: "foo.1.example.com",
+			path: "/a/",
+			want: TestHandler("default"),
+		},
+		{
+			name: "typed host falls back without host params",
+			host: "foo.1.example.com",
+			path: "/health",
+			want: TestHandler("health"),
+		},
+		{
+			name: "typed host not found",
+			host: "foo.1.example.com",
+			path: "/b/",
+			err:  notFoundError(http.MethodGet, "/b/"),
+		},
+		{
+			name: "static host",
+			host: "api.example.org",
+			path: "/a/",
+			want: TestHandler("api"),
+		},
+		{
+			name: "default tree",
+			host: "foo.bar.example.com",
+			path: "/a/",
+			want: TestHandler("default"),
+		},
+		{
+			name: "without host",
+			host: "",
+			path: "/a/",
+			want: TestHandler("default"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := mustReq(http.NewRequest(http.MethodGet, c.path, nil))
+			req.Host = c.host
+
+			got, err := mux.Handler(req)
+
+			as := Assert{t}
+			as.Equal(got, c.want, "ServeMux.Handler() got")
+			as.Equal(err, c.err, "ServeMux.Handler() error")
+			as.Equal(GetPathParams(req), c.params, "ServeMux.Handler() params")
+		})
+	}
+
+	t.Run("panic and keep tree on invalid host", func(t *testing.T) {
+		hosts := mux.hosts.String()
+
+		defer func() {
+			err := recover()
+			if err == nil || !errors.Is(err.(error), ErrMultiplePathParam) {
+				t.Errorf("ServeMux.Handle() got = %v, want = %v", err, ErrMultiplePathParam)
+			}
+
+			as := Assert{t}
+			as.StrEqual(mux.hosts.String(), hosts, "ServeMux.Handle() hosts")
+		}()
+
+		mux.Handle(http.MethodGet, "www.1.example.com/a/", TestHandler("www"))
+	})
+
+	t.Run("panic on empty host label", func(t *testing.T) {
+		defer func() {
+			err := recover()
+			if err == nil || !errors.Is(err.(error), ErrPattern) {
+				t.Errorf("ServeMux.Handle() got = %v, want = %v", err, ErrPattern)
+			}
+		}()
+
+		mux.Handle(http.MethodGet, "api..com/a/", TestHandler("api"))
+	})
+}
+
+func TestServeMuxHandleMatchers(t *testing.T) {
+	mux := New()
+	mux.Get("/a", TestHandler("v2"), WithMatchers(HeaderMatcher("X-API-Version", "2")))
+	mux.Get("/a", TestHandler("json"), WithMatchers(AcceptMatcher("application/json")))
+	mux.Post("/a", TestHandler("form"), WithMatchers(ContentTypeMatcher("application/x-www-form-urlencoded")))
+	mux.Get("/b", TestHandler("query"), WithMatchers(QueryMatcher("q", "")))
+	mux.Get("/b", TestHandler("fallback"))
+
+	cases := []struct {
+		name   string
+		method string
+		url    string
+		header http.Header
+		want   http.Handler
+		err    error
+	}{
+		{
+			name:   "header",
+			method: http.MethodGet,
+			url:    "/a",
+			header: http.Header{"X-Api-Version": {"2"}, "Accept": {"text/html"}},
+			want:   TestHandler("v2"),
+		},
+		{
+			name:   "accept",
+			method: http.MethodGet,
+			url:    "/a",
+			header: http.Header{"Accept": {"application/json"}},
+			want:   TestHandler("json"),
+		},
+		{
+			name:   "not acceptable",
+			method: http.MethodGet,
+			url:    "/a",
+			header: http.Header{"Accept": {"text/html"}},
+			err:    &ServeMuxError{http.MethodGet, "/a", ErrNotAcceptable},
+		},
+		{
+			name:   "unsupported media type",
+			method: http.MethodPost,
+			url:    "/a",
+			header: http.Header{"Content-Type": {"application/json"}},
+			err:    &ServeMuxError{http.MethodPost, "/a", ErrUnsupportedMediaType},
+		},
+		{
+			name:   "query",
+			method: http.MethodGet,
+			url:    "/b?q=1",
+			want:   TestHandler("query"),
+		},
+		{
+			name:   "fallback",
+			method: http.MethodGet,
+			url:    "/b",
+			want:   TestHandler("fallback"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := mustReq(http.NewRequest(c.method, c.url, nil))
+			req.Header = c.header
+
+			got, err := mux.Handler(req)
+
+			as := Assert{t}
+			as.Equal(got, c.want, "ServeMux.Handler() got")
+			as.Equal(err, c.err, "ServeMux.Handler() error")
+		})
+	}
+
+	t.Run("panic on unreachable route", func(t *testing.T) {
+		defer func() {
+			err := recover()
+			if err == nil || !errors.Is(err.(error), ErrDuplicate) {
+				t.Errorf("ServeMux.Handle() got = %v, want = %v", err, ErrDuplicate)
+			}
+		}()
+
+		mux.Get("/b", TestHandler("unreachable"), WithMatchers(SchemeMatcher("https")))
+	})
+}
+
 func TestServeMuxGet(t *testing.T) {
 	mux := New() // for direct compatibility (for not allocate tree)
 	exp := &node{
@@ -283,7 +918,11 @@ func TestServeMuxGet(t *testing.T) {
 			"/": {
 				tid: slash,
 				Methods: map[string]http.Handler{
-					http.MethodGet: TestHandler("get"),
+					http.MethodGet: candidates{{
+						method:  http.MethodGet,
+						pattern: "/",
+						handler: TestHandler("get"),
+					}},
 				},
 			},
 		},
@@ -302,7 +941,11 @@ func TestServeMuxHead(t *testing.T) {
 			"/": {
 				tid: slash,
 				Methods: map[string]http.Handler{
-					http.MethodHead: TestHandler("head"),
+					http.MethodHead: candidates{{
+						method:  http.MethodHead,
+						pattern: "/",
+						handler: TestHandler("head"),
+					}},
 				},
 			},
 		},
@@ -321,7 +964,11 @@ func TestServeMuxPost(t *testing.T) {
 			"/": {
 				tid: slash,
 				Methods: map[string]http.Handler{
-					http.MethodPost: TestHandler("post"),
+					http.MethodPost: candidates{{
+						method:  http.MethodPost,
+						pattern: "/",
+						handler: TestHandler("post"),
+					}},
 				},
 			},
 		},
@@ -340,7 +987,11 @@ func TestServeMuxPut(t *testing.T) {
 			"/": {
 				tid: slash,
 				Methods: map[string]http.Handler{
-					http.MethodPut: TestHandler("put"),
+					http.MethodPut: candidates{{
+						method:  http.MethodPut,
+						pattern: "/",
+						handler: TestHandler("put"),
+					}},
 				},
 			},
 		},
@@ -359,7 +1010,11 @@ func TestServeMuxPatch(t *testing.T) {
 			"/": {
 				tid: slash,
 				Methods: map[string]http.Handler{
-					http.MethodPatch: TestHandler("patch"),
+					http.MethodPatch: candidates{{
+						method:  http.MethodPatch,
+						pattern: "/",
+						handler: TestHandler("patch"),
+					}},
 				},
 			},
 		},
@@ -378,7 +1033,11 @@ func TestServeMuxDelete(t *testing.T) {
 			"/": {
 				tid: slash,
 				Methods: map[string]http.Handler{
-					http.MethodDelete: TestHandler("delete"),
+					http.MethodDelete: candidates{{
+						method:  http.MethodDelete,
+						pattern: "/",
+						handler: TestHandler("delete"),
+					}},
 				},
 			},
 		},
@@ -397,7 +1056,11 @@ func TestServeMuxConnect(t *testing.T) {
 			"/": {
 				tid: slash,
 				Methods: map[string]http.Handler{
-					http.MethodConnect: TestHandler("connect"),
+					http.MethodConnect: candidates{{
+						method:  http.MethodConnect,
+						pattern: "/",
+						handler: TestHandler("connect"),
+					}},
 				},
 			},
 		},
@@ -416,7 +1079,11 @@ func TestServeMuxOptions(t *testing.T) {
 			"/": {
 				tid: slash,
 				Methods: map[string]http.Handler{
-					http.MethodOptions: TestHandler("options"),
+					http.MethodOptions: candidates{{
+						method:  http.MethodOptions,
+						pattern: "/",
+						handler: TestHandler("options"),
+					}},
 				},
 			},
 		},
@@ -435,7 +1102,11 @@ func TestServeMuxTrace(t *testing.T) {
 			"/": {
 				tid: slash,
 				Methods: map[string]http.Handler{
-					http.MethodTrace: TestHandler("trace"),
+					http.MethodTrace: candidates{{
+						method:  http.MethodTrace,
+						pattern: "/",
+						handler: TestHandler("trace"),
+					}},
 				},
 			},
 		},
@@ -455,7 +1126,7 @@ func TestServeMuxHandleFunc(t *testing.T) {
 
 		defer func() {
 			err := recover()
-			if err == nil || errors.Unwrap(err.(error)) != ErrHandler {
+			if err == nil || !errors.Is(err.(error), ErrHandler) {
 				t.Errorf("ServeMux.HandleFunc() got = %v, want = %v", err, ErrHandler)
 			}
 
@@ -619,9 +1290,18 @@ func TestServeMuxServeHTTP(t *testing.T) {
 	ts := httptest.NewServer(mux)
 	defer ts.Close()
 
+	mux.GetFunc("/params/:int", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(GetRoute(r).Pattern + " " + strconv.Itoa(GetPathParams(r).MustInt(0))))
+	})
+
 	tc := ts.Client()
 	as := Assert{t}
 
+	respParams := mustResp(tc.Get(ts.URL + "/params/12"))
+	defer func() { _ = respParams.Body.Close() }()
+
+	as.StrEqual(mustRead(ioutil.ReadAll(respParams.Body)), "/params/:int 12", "path params and route")
+
 	respGood := mustResp(tc.Get(ts.URL))
 	defer func() { _ = respGood.Body.Close() }()
 
@@ -629,6 +1309,58 @@ func TestServeMuxServeHTTP(t *testing.T) {
 
 	respBad := mustResp(tc.Head(ts.URL))
 	as.IntEqual(respBad.StatusCode, http.StatusNotFound, "failure")
+
+	mux.Get("/json", TestHandler("json"), WithMatchers(AcceptMatcher("application/json")))
+
+	req := mustReq(http.NewRequest(http.MethodGet, ts.URL+"/json", nil))
+	req.Header.Set("Accept", "text/html")
+
+	respNotAcceptable := mustResp(tc.Do(req))
+	defer func() { _ = respNotAcceptable.Body.Close() }()
+
+	as.IntEqual(respNotAcceptable.StatusCode, http.StatusNotAcceptable, "not acceptable")
+}
+
+func TestServeMuxUse(t *testing.T) {
+	var calls []string
+
+	mw := func(s string) Middleware {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				pattern := ""
+				if rt := GetRoute(r); rt != nil {
+					pattern = rt.Pattern
+				}
+
+				calls = append(calls, s+" "+pattern)
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	mux := New()
+	mux.Use(mw("router"))
+	mux.GetFunc("/catalog/", func(http.ResponseWriter, *http.Request) { calls = append(calls, "get") })
+	mux.PostFunc("/catalog/", func(http.ResponseWriter, *http.Request) {
+		calls = append(calls, "post")
+	}, WithMiddleware(mw("auth")))
+
+	as := Assert{t}
+
+	mux.ServeHTTP(httptest.NewRecorder(), mustReq(http.NewRequest(http.MethodGet, "/catalog/", nil)))
+	as.Equal(calls, []string{"router /catalog/", "get"}, "without route middleware")
+
+	calls = nil
+
+	mux.ServeHTTP(httptest.NewRecorder(), mustReq(http.NewRequest(http.MethodPost, "/catalog/", nil)))
+	as.Equal(calls, []string{"router /catalog/", "auth /catalog/", "post"}, "with route middleware")
+
+	calls = nil
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, mustReq(http.NewRequest(http.MethodGet, "/catalog", nil)))
+	as.Equal(calls, []string{"router "}, "not found")
+	as.IntEqual(w.Code, http.StatusNotFound, "not found code")
 }
 
 func TestNew(t *testing.T) {
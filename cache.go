@@ -0,0 +1,336 @@
+package mixer
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxEntries   = 1000
+	defaultMaxBodyBytes = 1 << 20
+)
+
+type (
+	// Cache represents the in-memory response cache of the route.
+	Cache struct {
+		// TTL is how long the response is cached.
+		TTL time.Duration
+
+		// MaxEntries is the max number of the cached responses, the least recently
+		// used one is evicted when it is reached. Zero means 1000.
+		MaxEntries int
+
+		// MaxBodyBytes is the max size of the cached response body,
+		// the larger responses are not cached. Zero means 1MB.
+		MaxBodyBytes int
+	}
+
+	// cache represents the cached responses of the route in the LRU order.
+	cache struct {
+		Cache
+
+		mu      sync.Mutex
+		entries map[string]*list.Element
+		vary    map[string][]string // request headers which responses vary by params key
+		lru     *list.List
+		now     func() time.Time
+	}
+
+	// entry represents the cached response.
+	entry struct {
+		key     string
+		params  string
+		code    int
+		header  http.Header
+		body    []byte
+		stored  time.Time
+		expires time.Time
+	}
+
+	// cacheWriter represents the http.ResponseWriter which records the handler response.
+	cacheWriter struct {
+		http.ResponseWriter
+		header http.Header
+		sent   http.Header // snapshot of header at WriteHeader
+		code   int
+		body   bytes.Buffer
+		max    int
+		over   bool
+	}
+)
+
+// WithCache enables caching of the GET responses of the route keyed by
+// the canonical path param values, the raw query string and the request headers
+// listed in Vary, e.g. `/catalog/1?page=1` and `/catalog/1?page=2` are cached apart.
+// Only 200 responses without Set-Cookie and `Cache-Control: no-store|private`
+// are cached. The route middleware is run for every request, the handler is
+// run only on the cache miss. The responses can be removed by Invalidate.
+func WithCache(c Cache) RouteOption {
+	return func(rt *route) {
+		rt.cache = newCache(c)
+	}
+}
+
+// Invalidate removes the cached responses of the routes with name for params
+// (e.g. from the mutating handler). If params is nil all responses are removed.
+func (mux *ServeMux) Invalidate(name string, params PathParams) {
+	for _, rt := range mux.routes() {
+		if rt.name == name && rt.cache != nil {
+			rt.cache.invalidate(params)
+		}
+	}
+}
+
+// newCache returns the cache with defaults applied to c.
+func newCache(c Cache) *cache {
+	if c.MaxEntries < 1 {
+		c.MaxEntries = defaultMaxEntries
+	}
+
+	if c.MaxBodyBytes < 1 {
+		c.MaxBodyBytes = defaultMaxBodyBytes
+	}
+
+	return &cache{
+		Cache:   c,
+		entries: make(map[string]*list.Element),
+		vary:    make(map[string][]string),
+		lru:     list.New(),
+		now:     time.Now,
+	}
+}
+
+// paramsKey returns the canonical key of params, the values are ordered by index.
+func paramsKey(params PathParams) string {
+	idx := make([]int, 0, len(params))
+	for i := range params {
+		idx = append(idx, i)
+	}
+
+	sort.Ints(idx)
+
+	var b strings.Builder
+
+	for _, i := range idx {
+		fmt.Fprintf(&b, "%d=%q;", i, fmt.Sprint(params[i]))
+	}
+
+	return b.String()
+}
+
+// varyKey returns the key of the response for params key, the request query and headers names.
+func varyKey(params string, names []string, r *http.Request) string {
+	var b strings.Builder
+
+	b.WriteString(params)
+	fmt.Fprintf(&b, "?%q;", r.URL.RawQuery)
+
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%q;", name, strings.Join(r.Header[name], ","))
+	}
+
+	return b.String()
+}
+
+// handler returns next decorated by the cache.
+func (c *cache) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		params := paramsKey(GetPathParams(r))
+
+		if e := c.get(params, r); e != nil {
+			e.serve(w, c.now())
+			return
+		}
+
+		cw := &cacheWriter{ResponseWriter: w, header: make(http.Header), max: c.MaxBodyBytes}
+		next.ServeHTTP(cw, r)
+
+		if cw.code == 0 {
+			cw.WriteHeader(http.StatusOK)
+		}
+
+		c.put(params, r, cw)
+	})
+}
+
+// get returns the fresh cached response for r or nil otherwise.
+func (c *cache) get(params string, r *http.Request) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[varyKey(params, c.vary[params], r)]
+	if !ok {
+		return nil
+	}
+
+	e := el.Value.(*entry)
+	if !c.now().Before(e.expires) {
+		c.remove(el)
+		return nil
+	}
+
+	c.lru.MoveToFront(el)
+
+	return e
+}
+
+// put stores the response recorded by cw if it is cacheable.
+func (c *cache) put(params string, r *http.Request, cw *cacheWriter) {
+	if cw.code != http.StatusOK || cw.over || len(cw.sent["Set-Cookie"]) != 0 {
+		return
+	}
+
+	cc := strings.ToLower(strings.Join(cw.sent["Cache-Control"], ","))
+	if strings.Contains(cc, "no-store") || strings.Contains(cc, "private") {
+		return
+	}
+
+	var names []string
+
+	for _, v := range cw.sent["Vary"] {
+		for _, name := range strings.Split(v, ",") {
+			name = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))
+			if name == "*" {
+				return
+			}
+
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if strings.Join(c.vary[params], ",") != strings.Join(names, ",") {
+		c.invalidateKey(params)
+		c.vary[params] = names
+	}
+
+	now := c.now()
+	e := &entry{
+		key:     varyKey(params, names, r),
+		params:  params,
+		code:    cw.code,
+		header:  cw.sent,
+		body:    cw.body.Bytes(),
+		stored:  now,
+		expires: now.Add(c.TTL),
+	}
+
+	if el, ok := c.entries[e.key]; ok {
+		c.remove(el)
+	}
+
+	c.entries[e.key] = c.lru.PushFront(e)
+
+	for c.lru.Len() > c.MaxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// invalidate removes the responses for params or all of them if params is nil.
+func (c *cache) invalidate(params PathParams) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if params == nil {
+		c.entries = make(map[string]*list.Element)
+		c.vary = make(map[string][]string)
+		c.lru.Init()
+
+		return
+	}
+
+	c.invalidateKey(paramsKey(params))
+}
+
+// invalidateKey removes the responses for params key, c.mu must be held.
+func (c *cache) invalidateKey(params string) {
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+
+		if el.Value.(*entry).params == params {
+			c.remove(el)
+		}
+
+		el = next
+	}
+
+	delete(c.vary, params)
+}
+
+// remove removes the response of el, c.mu must be held.
+func (c *cache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*entry).key)
+}
+
+// serve writes the cached response with the Age header.
+func (e *entry) serve(w http.ResponseWriter, now time.Time) {
+	dst := w.Header()
+	for k, v := range e.header {
+		dst[k] = append(dst[k], v...)
+	}
+
+	dst.Set("Age", strconv.Itoa(int(now.Sub(e.stored).Seconds())))
+	w.WriteHeader(e.code)
+	_, _ = w.Write(e.body)
+}
+
+// Header implements the http.ResponseWriter's Header.
+// Only the headers set by the handler are recorded.
+func (cw *cacheWriter) Header() http.Header {
+	return cw.header
+}
+
+// WriteHeader implements the http.ResponseWriter's WriteHeader.
+func (cw *cacheWriter) WriteHeader(code int) {
+	if cw.code != 0 {
+		return
+	}
+
+	cw.code = code
+
+	dst := cw.ResponseWriter.Header()
+	for k, v := range cw.header {
+		dst[k] = append(dst[k], v...)
+	}
+
+	cw.sent = cw.header.Clone()
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements the http.ResponseWriter's Write.
+func (cw *cacheWriter) Write(b []byte) (int, error) {
+	if cw.code == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if !cw.over {
+		if cw.body.Len()+len(b) > cw.max {
+			cw.over = true
+			cw.body.Reset()
+		} else {
+			cw.body.Write(b)
+		}
+	}
+
+	return cw.ResponseWriter.Write(b)
+}
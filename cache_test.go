@@ -0,0 +1,108 @@
+package mixer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParamsKey(t *testing.T) {
+	as := Assert{t}
+	as.StrEqual(paramsKey(nil), "", "empty")
+	as.StrEqual(paramsKey(PathParams{1: "a;b", 0: 12}), `0="12";1="a;b";`, "ordered")
+}
+
+func TestServeMuxCache(t *testing.T) {
+	calls := 0
+	mux := New()
+	mux.GetFunc("/catalog/:int", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		switch GetPathParams(r).MustInt(0) {
+		case 2:
+			w.Header().Set("Vary", "Accept-Language")
+		case 3:
+			w.Header().Set("Cache-Control", "no-store")
+		case 4:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(strconv.Itoa(calls)))
+	}, WithName("catalog"), WithCache(Cache{TTL: time.Minute}))
+	mux.PutFunc("/catalog/:int", func(w http.ResponseWriter, r *http.Request) {
+		mux.Invalidate("catalog", GetPathParams(r))
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		url    string
+		lang   string
+		want   string
+		age    string
+	}{
+		{name: "miss", method: http.MethodGet, url: "/catalog/1", want: "1"},
+		{name: "hit", method: http.MethodGet, url: "/catalog/01", want: "1", age: "0"},
+		{name: "vary miss", method: http.MethodGet, url: "/catalog/2", lang: "en", want: "2"},
+		{name: "vary another", method: http.MethodGet, url: "/catalog/2", lang: "de", want: "3"},
+		{name: "vary hit", method: http.MethodGet, url: "/catalog/2", lang: "en", want: "2", age: "0"},
+		{name: "no-store", method: http.MethodGet, url: "/catalog/3", want: "4"},
+		{name: "no-store again", method: http.MethodGet, url: "/catalog/3", want: "5"},
+		{name: "not found", method: http.MethodGet, url: "/catalog/4", want: ""},
+		{name: "not found again", method: http.MethodGet, url: "/catalog/4", want: ""},
+		{name: "invalidate", method: http.MethodPut, url: "/catalog/1", want: ""},
+		{name: "invalidated", method: http.MethodGet, url: "/catalog/1", want: "8"},
+		{name: "not invalidated", method: http.MethodGet, url: "/catalog/2", lang: "de", want: "3", age: "0"},
+		{name: "query miss", method: http.MethodGet, url: "/catalog/1?page=2", want: "9"},
+		{name: "query hit", method: http.MethodGet, url: "/catalog/1?page=2", want: "9", age: "0"},
+		{name: "without query hit", method: http.MethodGet, url: "/catalog/1", want: "8", age: "0"},
+	}
+
+	for _, c := range cases {
+		req := mustReq(http.NewRequest(c.method, c.url, nil))
+		req.Header.Set("Accept-Language", c.lang)
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		as := Assert{t}
+		as.StrEqual(w.Body.String(), c.want, c.name+" body")
+		as.StrEqual(w.Header().Get("Age"), c.age, c.name+" Age")
+	}
+}
+
+func TestCacheBounds(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newCache(Cache{TTL: time.Second, MaxEntries: 2, MaxBodyBytes: 3})
+	c.now = func() time.Time { return now }
+
+	h := c.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+
+	serve := func(path string) {
+		req := mustReq(http.NewRequest(http.MethodGet, path, nil))
+		h.ServeHTTP(httptest.NewRecorder(), Match{Params: PathParams{0: path}}.request(req))
+	}
+
+	serve("/a")
+	serve("/b")
+	serve("/c")
+	serve("/long")
+
+	as := Assert{t}
+	as.IntEqual(c.lru.Len(), 2, "max entries")
+	as.BoolEqual(c.entries[paramsKey(PathParams{0: "/a"})] == nil, true, "evicted")
+
+	now = now.Add(time.Second)
+	req := Match{Params: PathParams{0: "/c"}}.request(mustReq(http.NewRequest(http.MethodGet, "/c", nil)))
+
+	as.BoolEqual(c.get(paramsKey(PathParams{0: "/c"}), req) == nil, true, "expired")
+	as.IntEqual(c.lru.Len(), 1, "expired removed")
+
+	c.invalidate(nil)
+	as.IntEqual(c.lru.Len(), 0, "invalidate all")
+}
@@ -0,0 +1,397 @@
+// Command mixer-gen generates typed handler interfaces and adapters for mixer routes.
+//
+// The routes are declared in the route files (see mixer.LoadFile) or in Go comments:
+//
+//	//mixer:route GET /catalog/:int RetrieveCatalog(id) catalog
+//
+// where the last word is an optional route name. The handler of the declaration is
+// the method name with optional names of path params in order of the pattern,
+// the catch-all `*` is the string path param.
+// For the declaration above the interface method is
+//
+//	RetrieveCatalog(w http.ResponseWriter, r *http.Request, id int)
+//
+// and the generated RegisterAPI and APIHandlers functions adapt it to http.Handler
+// extracting the path params with the correct types.
+//
+// Usage:
+//
+//	mixer-gen [-o file] [-pkg name] [-type name] files or dirs...
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kxnes/mixer"
+	"github.com/kxnes/mixer/internal/decl"
+)
+
+type (
+	// route represents the declared route with parsed handler.
+	route struct {
+		mixer.RouteDecl
+
+		method string // the method of the interface
+		params []param
+		source string // the position of declaration for errors
+	}
+
+	// param represents the path param of the route.
+	param struct {
+		index int
+		name  string
+		typ   string
+		get   string
+	}
+)
+
+func main() {
+	out := flag.String("o", "", "output file, stdout if empty")
+	pkg := flag.String("pkg", "", "package name, detected from the Go files if empty")
+	typ := flag.String("type", "API", "name of the handlers interface")
+
+	flag.Parse()
+
+	if err := run(flag.Args(), *out, *pkg, *typ); err != nil {
+		fmt.Fprintln(os.Stderr, "mixer-gen:", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the code for the declarations of paths and writes it to out.
+func run(paths []string, out, pkg, typ string) error {
+	if len(paths) == 0 {
+		return errors.New("no input files")
+	}
+
+	var decls []route
+
+	for _, path := range paths {
+		rs, p, err := read(path)
+		if err != nil {
+			return err
+		}
+
+		if pkg == "" {
+			pkg = p
+		}
+
+		decls = append(decls, rs...)
+	}
+
+	if pkg == "" {
+		pkg = "main"
+	}
+
+	src, err := generate(decls, pkg, typ)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+
+	return ioutil.WriteFile(out, src, 0o644)
+}
+
+// read returns the declarations of the route file, Go file or directory of Go files
+// and the package name of Go files if any.
+func read(path string) ([]route, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !info.IsDir() && filepath.Ext(path) != ".go" {
+		decls, err := mixer.ReadRouteFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", path, err)
+		}
+
+		rs := make([]route, 0, len(decls))
+		for _, d := range decls {
+			rs = append(rs, route{RouteDecl: d, source: path + ":" + strconv.Itoa(d.Line)})
+		}
+
+		return rs, "", nil
+	}
+
+	files := []string{path}
+
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.go")); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var (
+		rs  []route
+		pkg string
+	)
+
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		frs, p, err := readGo(file)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if pkg == "" {
+			pkg = p
+		}
+
+		rs = append(rs, frs...)
+	}
+
+	return rs, pkg, nil
+}
+
+// readGo returns the declarations of the Go file comments and the package name.
+func readGo(file string) ([]route, string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	var (
+		rs  []route
+		pkg string
+	)
+
+	sc := bufio.NewScanner(f)
+
+	for l := 1; sc.Scan(); l++ {
+		text := sc.Text()
+
+		if pkg == "" && strings.HasPrefix(text, "package ") {
+			pkg = strings.TrimSpace(strings.TrimPrefix(text, "package "))
+		}
+
+		d, ok := decl.Directive(text)
+		if !ok {
+			continue
+		}
+
+		d.Line = l
+		rs = append(rs, route{RouteDecl: d, source: file + ":" + strconv.Itoa(l)})
+	}
+
+	return rs, pkg, sc.Err()
+}
+
+// parse parses the handler and the pattern of the declaration to the method and params.
+func (rt *route) parse() error {
+	rt.method = rt.Handler
+
+	var names []string
+
+	if i := strings.IndexByte(rt.Handler, '('); i >= 0 {
+		if !strings.HasSuffix(rt.Handler, ")") {
+			return fmt.Errorf("%s: invalid handler %q", rt.source, rt.Handler)
+		}
+
+		rt.method = rt.Handler[:i]
+
+		if args := rt.Handler[i+1 : len(rt.Handler)-1]; args != "" {
+			names = strings.Split(args, ",")
+		}
+	}
+
+	if !token.IsIdentifier(rt.method) || !token.IsExported(rt.method) {
+		return fmt.Errorf("%s: handler %q is not exported identifier", rt.source, rt.method)
+	}
+
+	pp, err := mixer.New().ParsePattern(rt.Pattern)
+	if err != nil {
+		return fmt.Errorf("%s: pattern %q: %w", rt.source, rt.Pattern, err)
+	}
+
+	for _, seg := range append(pp.Host, pp.Path...) {
+		conv := strings.TrimPrefix(seg.Value, ":")
+
+		switch seg.Kind {
+		case mixer.ParamNode:
+		case mixer.CatchAllNode: // the catch-all is the rest of the path
+			conv = ""
+		default:
+			continue
+		}
+
+		typ, get, ok := accessor(conv)
+		if !ok {
+			return fmt.Errorf("%s: unknown path param type %q", rt.source, conv)
+		}
+
+		p := param{index: len(rt.params), name: "p" + strconv.Itoa(len(rt.params)), typ: typ, get: get}
+
+		if p.index < len(names) {
+			p.name = strings.TrimSpace(names[p.index])
+		}
+
+		if !token.IsIdentifier(p.name) || p.name == "w" || p.name == "r" {
+			return fmt.Errorf("%s: invalid path param name %q", rt.source, p.name)
+		}
+
+		rt.params = append(rt.params, p)
+	}
+
+	if len(names) > len(rt.params) {
+		return fmt.Errorf("%s: %d names for %d path params", rt.source, len(names), len(rt.params))
+	}
+
+	return nil
+}
+
+// accessor returns the Go type and PathParams accessor of the path param converter.
+func accessor(conv string) (string, string, bool) {
+	switch conv {
+	case "", "str":
+		return "string", "MustString", true
+	case "int":
+		return "int", "MustInt", true
+	default:
+		return "", "", false
+	}
+}
+
+// signature returns the parameters of the interface method.
+func (rt *route) signature() string {
+	args := []string{"w http.ResponseWriter", "r *http.Request"}
+
+	for _, p := range rt.params {
+		args = append(args, p.name+" "+p.typ)
+	}
+
+	return strings.Join(args, ", ")
+}
+
+// generate returns the formatted Go code for the declarations.
+func generate(rs []route, pkg, typ string) ([]byte, error) {
+	methods := make(map[string]*route)
+
+	for i := range rs {
+		rt := &rs[i]
+		if err := rt.parse(); err != nil {
+			return nil, err
+		}
+
+		prev, ok := methods[rt.method]
+		if !ok {
+			methods[rt.method] = rt
+			continue
+		}
+
+		if prev.signature() != rt.signature() {
+			return nil, fmt.Errorf("%s: handler %s redeclared with another signature at %s",
+				rt.source, rt.method, prev.source)
+		}
+	}
+
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by mixer-gen. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	fmt.Fprintf(&b, "import (\n\"net/http\"\n\n\"github.com/kxnes/mixer\"\n)\n\n")
+
+	writeInterface(&b, methods, typ)
+	writeRegister(&b, rs, typ)
+	writeHandlers(&b, rs, typ)
+
+	return format.Source(b.Bytes())
+}
+
+// writeInterface writes the interface typ with methods sorted by name.
+func writeInterface(b *bytes.Buffer, methods map[string]*route, typ string) {
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintf(b, "// %s represents the typed handlers of the routes.\ntype %s interface {\n", typ, typ)
+
+	for i, name := range names {
+		if i != 0 {
+			b.WriteString("\n")
+		}
+
+		rt := methods[name]
+		fmt.Fprintf(b, "// %s handles %s %s.\n%s(%s)\n", name, rt.Method, rt.Pattern, name, rt.signature())
+	}
+
+	b.WriteString("}\n\n")
+}
+
+// writeRegister writes the function which registers the routes rs.
+func writeRegister(b *bytes.Buffer, rs []route, typ string) {
+	fmt.Fprintf(b, "// Register%s registers the routes of h in mux with opts.\n", typ)
+	fmt.Fprintf(b, "func Register%s(mux *mixer.ServeMux, h %s, opts ...mixer.RouteOption) {\n", typ, typ)
+
+	for _, rt := range rs {
+		fmt.Fprintf(b, "mux.Handle(%q, %q, %s", rt.Method, rt.Pattern, adapter(rt))
+
+		if rt.Name != "" {
+			fmt.Fprintf(b, ", append([]mixer.RouteOption{mixer.WithName(%q)}, opts...)...)\n", rt.Name)
+		} else {
+			b.WriteString(", opts...)\n")
+		}
+	}
+
+	b.WriteString("}\n\n")
+}
+
+// writeHandlers writes the function which returns the registry for mixer.LoadFile.
+func writeHandlers(b *bytes.Buffer, rs []route, typ string) {
+	fmt.Fprintf(b, "// %sHandlers returns the adapters of h by the handlers of declarations for mixer.LoadFile.\n", typ)
+	fmt.Fprintf(b, "func %sHandlers(h %s) mixer.Handlers {\nreturn mixer.Handlers{\n", typ, typ)
+
+	seen := make(map[string]bool)
+
+	for _, rt := range rs {
+		if !seen[rt.Handler] {
+			seen[rt.Handler] = true
+			fmt.Fprintf(b, "%q: %s,\n", rt.Handler, adapter(rt))
+		}
+	}
+
+	b.WriteString("}\n}\n")
+}
+
+// adapter returns the code of http.Handler calling the interface method of rt.
+func adapter(rt route) string {
+	var b strings.Builder
+
+	b.WriteString("http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {\n")
+
+	args := []string{"w", "r"}
+
+	if len(rt.params) != 0 {
+		b.WriteString("params := mixer.GetPathParams(r)\n")
+	}
+
+	for _, p := range rt.params {
+		args = append(args, "params."+p.get+"("+strconv.Itoa(p.index)+")")
+	}
+
+	fmt.Fprintf(&b, "h.%s(%s)\n})", rt.method, strings.Join(args, ", "))
+
+	return b.String()
+}
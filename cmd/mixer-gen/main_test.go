@@ -0,0 +1,138 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kxnes/mixer"
+)
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mixer-gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := `package catalog
+
+//mixer:route GET /catalog/:int RetrieveCatalog(id) catalog
+// mixer:route DELETE /catalog/:int RetrieveCatalog(id)
+//mixer:route POST /catalog CreateCatalog
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "api.go"), []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rs, pkg, err := read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := generate(rs, pkg, "Catalog")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `// Code generated by mixer-gen. DO NOT EDIT.
+
+package catalog
+
+import (
+	"net/http"
+
+	"github.com/kxnes/mixer"
+)
+
+// Catalog represents the typed handlers of the routes.
+type Catalog interface {
+	// CreateCatalog handles POST /catalog.
+	CreateCatalog(w http.ResponseWriter, r *http.Request)
+
+	// RetrieveCatalog handles GET /catalog/:int.
+	RetrieveCatalog(w http.ResponseWriter, r *http.Request, id int)
+}
+
+// RegisterCatalog registers the routes of h in mux with opts.
+func RegisterCatalog(mux *mixer.ServeMux, h Catalog, opts ...mixer.RouteOption) {
+	mux.Handle("GET", "/catalog/:int", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := mixer.GetPathParams(r)
+		h.RetrieveCatalog(w, r, params.MustInt(0))
+	}), append([]mixer.RouteOption{mixer.WithName("catalog")}, opts...)...)
+	mux.Handle("DELETE", "/catalog/:int", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := mixer.GetPathParams(r)
+		h.RetrieveCatalog(w, r, params.MustInt(0))
+	}), opts...)
+	mux.Handle("POST", "/catalog", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.CreateCatalog(w, r)
+	}), opts...)
+}
+`
+
+	if !strings.HasPrefix(string(got), want) {
+		t.Errorf("generate() got:\n%s\nwant prefix:\n%s", got, want)
+	}
+}
+
+func TestRouteParseCatchAll(t *testing.T) {
+	rt := route{RouteDecl: mixer.RouteDecl{Method: "GET", Pattern: "/static/:int/*", Handler: "Files(id, name)"}}
+	if err := rt.parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []param{
+		{index: 0, name: "id", typ: "int", get: "MustInt"},
+		{index: 1, name: "name", typ: "string", get: "MustString"},
+	}
+
+	if !reflect.DeepEqual(rt.params, want) {
+		t.Errorf("route.parse() params: %+v, want: %+v", rt.params, want)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		pattern string
+		handler string
+		want    string
+	}{
+		{name: "not exported", pattern: "/a", handler: "get", want: `handler "get" is not exported identifier`},
+		{name: "unclosed", pattern: "/a", handler: "Get(id", want: `invalid handler "Get(id"`},
+		{
+			name: "unknown type", pattern: "/a/:uuid", handler: "Get",
+			want: `pattern "/a/:uuid": unknown converter 'uuid' at offset 3`,
+		},
+		{
+			name: "inner catch-all", pattern: "/a/*/b", handler: "Get(name)",
+			want: `pattern "/a/*/b": catch-all is not the last path part at offset 3`,
+		},
+		{name: "empty part", pattern: "/c//d", handler: "Other", want: `pattern "/c//d": empty path part at offset 3`},
+		{name: "invalid name", pattern: "/a/:int", handler: "Get(r)", want: `invalid path param name "r"`},
+		{name: "extra names", pattern: "/a/:int", handler: "Get(a, b)", want: "2 names for 1 path params"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rs := []route{{RouteDecl: mixer.RouteDecl{Method: "GET", Pattern: c.pattern, Handler: c.handler}, source: "f:1"}}
+
+			_, err := generate(rs, "p", "API")
+			if err == nil || err.Error() != "f:1: "+c.want {
+				t.Errorf("generate() error: %v, want: %s", err, c.want)
+			}
+		})
+	}
+
+	rs := []route{
+		{RouteDecl: mixer.RouteDecl{Method: "GET", Pattern: "/a/:int", Handler: "Get"}, source: "f:1"},
+		{RouteDecl: mixer.RouteDecl{Method: "GET", Pattern: "/b/:str", Handler: "Get"}, source: "f:2"},
+	}
+
+	if _, err := generate(rs, "p", "API"); err == nil || !strings.Contains(err.Error(), "redeclared") {
+		t.Errorf("generate() redeclared error: %v", err)
+	}
+}
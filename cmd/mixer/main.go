@@ -0,0 +1,464 @@
+// Command mixer inspects the route table without a running server.
+//
+// The routes are loaded from the route files (see mixer.LoadFile) and Go files
+// or directories: the `//mixer:route` comments of mixer-gen and the registrations
+// with literal method and pattern like the ones of the generated files, e.g.
+//
+//	mux.Handle("GET", "/catalog/:int", h, mixer.WithName("catalog"))
+//	mux.GetFunc("/catalog/", list)
+//
+// Only the registrations on the ServeMux values declared as `*mixer.ServeMux` or by `mixer.New`
+// in the same file are loaded. The routes of groups are not resolved because their prefix is not literal.
+//
+// Usage:
+//
+//	mixer -f file [-f file]... routes
+//	mixer -f file [-f file]... tree
+//	mixer -f file [-f file]... [-H 'Key: Value']... match METHOD URL
+//
+// The match command uses ServeMux.Match, so the request is routed exactly as by ServeMux.Handler.
+// The matching options of the ServeMux are set by the flags:
+//
+//	-raw       mixer.WithRawPathParams()
+//	-ci        mixer.WithCaseInsensitive(false)
+//	-redirect  mixer.WithCaseInsensitive(true)
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kxnes/mixer"
+	"github.com/kxnes/mixer/internal/decl"
+)
+
+type (
+	// handler represents the stub of the loaded route handler, it is the handler name.
+	handler string
+
+	// list represents the repeated flag value.
+	list []string
+)
+
+// ServeHTTP implements a Handler's interface.
+func (h handler) ServeHTTP(http.ResponseWriter, *http.Request) {}
+
+// String implements the flag.Value's String.
+func (l *list) String() string {
+	return strings.Join(*l, ", ")
+}
+
+// Set implements the flag.Value's Set.
+func (l *list) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "mixer:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command of args and writes its output to w.
+func run(args []string, w io.Writer) error {
+	var files, headers list
+
+	fs := flag.NewFlagSet("mixer", flag.ContinueOnError)
+	fs.Var(&files, "f", "route file, Go file or directory, can be repeated")
+	fs.Var(&headers, "H", "request header `Key: Value` for match, can be repeated")
+	raw := fs.Bool("raw", false, "match the path parts without percent-decoding (mixer.WithRawPathParams)")
+	fold := fs.Bool("ci", false, "match the static parts case-insensitively (mixer.WithCaseInsensitive)")
+	redirect := fs.Bool("redirect", false, "like -ci but redirect to the canonical path")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if len(files) == 0 || fs.NArg() == 0 {
+		return errors.New("usage: mixer -f file [-H header] [-raw] [-ci|-redirect] routes|tree|match METHOD URL")
+	}
+
+	var opts []mixer.Option
+
+	if *raw {
+		opts = append(opts, mixer.WithRawPathParams())
+	}
+
+	if *fold || *redirect {
+		opts = append(opts, mixer.WithCaseInsensitive(*redirect))
+	}
+
+	mux := mixer.New(opts...)
+
+	for _, file := range files {
+		if err := load(mux, file); err != nil {
+			return err
+		}
+	}
+
+	switch cmd := fs.Arg(0); {
+	case cmd == "routes" && fs.NArg() == 1:
+		return writeRoutes(w, mux)
+	case cmd == "tree" && fs.NArg() == 1:
+		return mux.WriteTree(w)
+	case cmd == "match" && fs.NArg() == 3:
+		return match(w, mux, fs.Arg(1), fs.Arg(2), headers)
+	default:
+		return fmt.Errorf("unknown command %q", strings.Join(fs.Args(), " "))
+	}
+}
+
+// load registers the routes of the route file, Go file or directory of Go files.
+func load(mux *mixer.ServeMux, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() && filepath.Ext(path) != ".go" {
+		decls, err := mixer.ReadRouteFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		handlers := make(mixer.Handlers)
+		for _, d := range decls {
+			handlers[d.Handler] = handler(d.Handler)
+		}
+
+		if err := mux.LoadFile(path, handlers); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		return nil
+	}
+
+	files := []string{path}
+
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.go")); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		if err := loadGo(mux, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadGo registers the routes of the directives and registrations of the Go file.
+func loadGo(mux *mixer.ServeMux, file string) error {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	type entry struct {
+		pos  token.Pos
+		reg  mixer.Registration
+		name string
+	}
+
+	var decls []entry
+
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			if d, ok := decl.Directive(c.Text); ok {
+				reg := mixer.Registration{Method: d.Method, Pattern: d.Pattern, Handler: handler(d.Handler)}
+				decls = append(decls, entry{c.Pos(), reg, d.Name})
+			}
+		}
+	}
+
+	pkg := importName(f, "github.com/kxnes/mixer")
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && pkg != "" {
+			if reg, name, ok := registration(call, pkg); ok {
+				decls = append(decls, entry{call.Pos(), reg, name})
+			}
+		}
+
+		return true
+	})
+
+	sort.SliceStable(decls, func(i, j int) bool { return decls[i].pos < decls[j].pos })
+
+	for _, d := range decls {
+		if d.name != "" {
+			d.reg.Options = []mixer.RouteOption{mixer.WithName(d.name)}
+		}
+
+		if err := mux.TryHandle(d.reg.Method, d.reg.Pattern, d.reg.Handler, d.reg.Options...); err != nil {
+			return fmt.Errorf("%s: %w", fset.Position(d.pos), err)
+		}
+	}
+
+	return nil
+}
+
+// importName returns the name of the package path imported by f or empty string if it is not imported.
+func importName(f *ast.File, path string) string {
+	for _, spec := range f.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err != nil || p != path {
+			continue
+		}
+
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+
+		return filepath.Base(path)
+	}
+
+	return ""
+}
+
+// registration returns the route of call if it is the registration with literal method and pattern
+// on the ServeMux of the mixer package imported as pkg and the route name of mixer.WithName option if any.
+func registration(call *ast.CallExpr, pkg string) (mixer.Registration, string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !isServeMux(sel.X, pkg) {
+		return mixer.Registration{}, "", false
+	}
+
+	method, i, ok := decl.Registration(sel.Sel.Name)
+	if !ok || len(call.Args) < i+2 {
+		return mixer.Registration{}, "", false
+	}
+
+	if method == "" {
+		if method, ok = methodOf(call.Args[0]); !ok {
+			return mixer.Registration{}, "", false
+		}
+	}
+
+	args := call.Args[i:]
+
+	pattern, ok := literal(args[0])
+	if !ok {
+		return mixer.Registration{}, "", false
+	}
+
+	var name string
+
+	for _, arg := range args[2:] {
+		ast.Inspect(arg, func(n ast.Node) bool {
+			if c, ok := n.(*ast.CallExpr); ok && len(c.Args) == 1 {
+				if s, ok := c.Fun.(*ast.SelectorExpr); ok && s.Sel.Name == "WithName" {
+					name, _ = literal(c.Args[0])
+				}
+			}
+
+			return name == ""
+		})
+	}
+
+	return mixer.Registration{Method: method, Pattern: pattern, Handler: handler(handlerName(args[1]))}, name, true
+}
+
+// isServeMux reports whether expr is the identifier declared as *pkg.ServeMux or by pkg.New.
+// The declaration is resolved by the parser, so only the identifiers of the same file are known.
+func isServeMux(expr ast.Expr, pkg string) bool {
+	id, ok := expr.(*ast.Ident)
+	if !ok || id.Obj == nil {
+		return false
+	}
+
+	// value reports whether the i-th of values is the call of pkg.New.
+	value := func(values []ast.Expr, i int) bool {
+		if len(values) <= i {
+			return false
+		}
+
+		call, ok := values[i].(*ast.CallExpr)
+
+		return ok && isSelector(call.Fun, pkg, "New")
+	}
+
+	switch d := id.Obj.Decl.(type) {
+	case *ast.Field:
+		return isPointer(d.Type, pkg, "ServeMux")
+	case *ast.ValueSpec:
+		for i, name := range d.Names {
+			if name.Name == id.Name {
+				return isPointer(d.Type, pkg, "ServeMux") || value(d.Values, i)
+			}
+		}
+	case *ast.AssignStmt:
+		for i, lhs := range d.Lhs {
+			if l, ok := lhs.(*ast.Ident); ok && l.Name == id.Name {
+				return len(d.Lhs) == len(d.Rhs) && value(d.Rhs, i)
+			}
+		}
+	}
+
+	return false
+}
+
+// isPointer reports whether expr is the pointer type *pkg.name.
+func isPointer(expr ast.Expr, pkg, name string) bool {
+	star, ok := expr.(*ast.StarExpr)
+	return ok && isSelector(star.X, pkg, name)
+}
+
+// isSelector reports whether expr is the selector pkg.name.
+func isSelector(expr ast.Expr, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+
+	id, ok := sel.X.(*ast.Ident)
+
+	return ok && id.Name == pkg
+}
+
+// methodOf returns the method of the string literal or net/http constant.
+func methodOf(expr ast.Expr) (string, bool) {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if !strings.HasPrefix(sel.Sel.Name, "Method") {
+			return "", false
+		}
+
+		m, _, ok := decl.Registration(strings.TrimPrefix(sel.Sel.Name, "Method"))
+
+		return m, ok && m != ""
+	}
+
+	return literal(expr)
+}
+
+// literal returns the value of the string literal.
+func literal(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+
+	s, err := strconv.Unquote(lit.Value)
+
+	return s, err == nil
+}
+
+// handlerName returns the readable name of the handler expression.
+// For the func literal (e.g. mixer-gen adapter) it is the function called by its last statement.
+func handlerName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		if len(e.Args) == 1 {
+			if _, ok := e.Args[0].(*ast.FuncLit); ok {
+				return handlerName(e.Args[0])
+			}
+		}
+	case *ast.FuncLit:
+		if n := len(e.Body.List); n != 0 {
+			if stmt, ok := e.Body.List[n-1].(*ast.ExprStmt); ok {
+				if call, ok := stmt.X.(*ast.CallExpr); ok {
+					return types.ExprString(call.Fun)
+				}
+			}
+		}
+
+		return "func literal"
+	}
+
+	return types.ExprString(expr)
+}
+
+// writeRoutes writes the table of the registered routes.
+func writeRoutes(w io.Writer, mux *mixer.ServeMux) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "METHOD\tPATTERN\tNAME\tHANDLER")
+
+	for _, rt := range mux.Routes() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", rt.Method, rt.Pattern, rt.Name, name(rt.Handler))
+	}
+
+	return tw.Flush()
+}
+
+// match writes the route and params which the request of method, URL and headers hits.
+// The error of matching is returned after the allowed methods are written.
+func match(w io.Writer, mux *mixer.ServeMux, method, url string, headers []string) error {
+	r, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range headers {
+		i := strings.IndexByte(h, ':')
+		if i < 0 {
+			return fmt.Errorf("invalid header %q", h)
+		}
+
+		r.Header.Add(strings.TrimSpace(h[:i]), strings.TrimSpace(h[i+1:]))
+	}
+
+	m, err := mux.Match(r)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	if err == nil {
+		fmt.Fprintf(tw, "pattern\t%s\n", m.Pattern)
+		fmt.Fprintf(tw, "name\t%s\n", m.Name)
+		fmt.Fprintf(tw, "handler\t%s\n", name(m.Handler))
+		fmt.Fprintf(tw, "params\t%s\n", params(m.Params))
+	}
+
+	if len(m.Methods) != 0 {
+		fmt.Fprintf(tw, "methods\t%s\n", strings.Join(m.Methods, " "))
+	}
+
+	if ferr := tw.Flush(); ferr != nil {
+		return ferr
+	}
+
+	return err
+}
+
+// name returns the name of the loaded handler or `redirect` for the canonical redirect.
+func name(h http.Handler) string {
+	if h, ok := h.(handler); ok {
+		return string(h)
+	}
+
+	return "redirect"
+}
+
+// params returns the path params in order of index with their types, e.g. `0=42 (int)`.
+func params(p mixer.PathParams) string {
+	out := make([]string, 0, len(p))
+
+	for i := 0; i < len(p); i++ {
+		out = append(out, fmt.Sprintf("%d=%v (%T)", i, p[i], p[i]))
+	}
+
+	return strings.Join(out, " ")
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kxnes/mixer"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mixer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := `package catalog
+
+import (
+	"net/http"
+
+	"github.com/kxnes/mixer"
+)
+
+//mixer:route DELETE /catalog/:int DeleteCatalog
+
+func RegisterAPI(mux *mixer.ServeMux, h API, opts ...mixer.RouteOption) {
+	mux.Handle("GET", "/catalog/:int", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := mixer.GetPathParams(r)
+		h.RetrieveCatalog(w, r, params.MustInt(0))
+	}), append([]mixer.RouteOption{mixer.WithName("catalog")}, opts...)...)
+	mux.HandleFunc(http.MethodPost, "/catalog/", create)
+	mux.Get(":str.example.com/", index, mixer.WithMatchers(mixer.HeaderMatcher("X-Tenant", "1")))
+	mux.Get(pattern, index)
+
+	g := mux.Group("/v1")
+	g.Get("/items", index)
+	cache.Get("/users", index)
+}
+
+func RegisterLocal() {
+	local := mixer.New()
+	local.Put("/local", index)
+}
+`
+	file := filepath.Join(dir, "api.go")
+	if err := ioutil.WriteFile(file, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		want string
+		err  error
+	}{
+		{
+			name: "routes",
+			args: []string{"-f", dir, "routes"},
+			want: `METHOD  PATTERN            NAME     HANDLER
+POST    /catalog/                   create
+DELETE  /catalog/:int               DeleteCatalog
+GET     /catalog/:int      catalog  h.RetrieveCatalog
+PUT     /local                      index
+GET     :str.example.com/           index
+`,
+		},
+		{
+			name: "tree",
+			args: []string{"-f", file, "tree"},
+			want: `com
+  example
+    :str
+      /
+        / [GET]
+/
+  catalog
+    / [POST]
+    :int [DELETE GET]
+  local [PUT]
+`,
+		},
+		{
+			name: "match",
+			args: []string{"-f", file, "-H", "X-Tenant: 1", "match", "GET", "http://shop.example.com/"},
+			want: `pattern  :str.example.com/
+name     
+handler  index
+params   0=shop (string)
+methods  GET
+`,
+		},
+		{
+			name: "match not found",
+			args: []string{"-f", file, "match", "PUT", "/catalog/42"},
+			want: "methods  DELETE GET\n",
+			err:  mixer.ErrNotFound,
+		},
+		{
+			name: "match case-sensitive",
+			args: []string{"-f", file, "match", "GET", "/Catalog/42"},
+			want: "",
+			err:  mixer.ErrNotFound,
+		},
+		{
+			name: "match case-insensitive",
+			args: []string{"-f", file, "-ci", "match", "GET", "/Catalog/42"},
+			want: `pattern  /catalog/:int
+name     catalog
+handler  h.RetrieveCatalog
+params   0=42 (int)
+methods  DELETE GET
+`,
+		},
+		{
+			name: "match redirect",
+			args: []string{"-f", file, "-redirect", "match", "GET", "/Catalog/42"},
+			want: `pattern  /catalog/:int
+name     catalog
+handler  redirect
+params   0=42 (int)
+methods  DELETE GET
+`,
+		},
+		{
+			name: "match raw",
+			args: []string{"-f", file, "-raw", "match", "GET", "/catalog/%34%32"},
+			want: "",
+			err:  mixer.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b strings.Builder
+
+			err := run(tc.args, &b)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("run() error = %v, want %v", err, tc.err)
+			}
+
+			if got := b.String(); got != tc.want {
+				t.Errorf("run() got\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mixer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "api.go")
+	src := "package api\n\nimport \"github.com/kxnes/mixer\"\n\nvar mux = mixer.New()\n\nfunc init() {\n\tmux.Get(\"/a\", a)\n\tmux.Get(\"/a\", b)\n}\n"
+
+	if err := ioutil.WriteFile(file, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err = run([]string{"-f", file, "routes"}, ioutil.Discard)
+	if !errors.Is(err, mixer.ErrDuplicate) || !strings.Contains(err.Error(), "api.go:9:2") {
+		t.Errorf("run() error = %v, want duplicate at api.go:9:2", err)
+	}
+
+	if err := run([]string{"-f", file}, ioutil.Discard); err == nil {
+		t.Error("run() without command error = nil")
+	}
+}
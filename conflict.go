@@ -0,0 +1,138 @@
+package mixer
+
+import (
+	"net/http"
+	"strconv"
+)
+
+type (
+	// NodeKind represents the kind of the pattern segment in the tree.
+	NodeKind int
+
+	// Conflict describes the existing route which the registered one conflicts with.
+	// It wraps ErrMultiplePathParam or ErrDuplicate and can be got by ServeMuxError.Conflict.
+	Conflict struct {
+		// Method and Pattern are of the existing route.
+		Method  string
+		Pattern string
+
+		// Segment is the index of the conflicting segment of the registered pattern,
+		// the host labels come first and then the path parts. It is -1 for ErrDuplicate.
+		Segment int
+
+		// Kind and ExistingKind are the kinds of the conflicting segments
+		// of the registered and the existing patterns.
+		Kind         NodeKind
+		ExistingKind NodeKind
+
+		err error
+	}
+)
+
+const (
+	// StaticNode is the kind of the static segment, e.g. `catalog`.
+	StaticNode NodeKind = other
+
+	// ParamNode is the kind of the path param segment, e.g. `:int`.
+	ParamNode NodeKind = param
+
+	// SlashNode is the kind of the trailing slash segment.
+	SlashNode NodeKind = slash
+
+	// CatchAllNode is the kind of the last `*` segment which matches the rest of the path.
+	CatchAllNode NodeKind = catchall
+)
+
+// String implements the fmt.Stringer's String.
+func (k NodeKind) String() string {
+	switch k {
+	case StaticNode:
+		return "static"
+	case ParamNode:
+		return "param"
+	case SlashNode:
+		return "slash"
+	case CatchAllNode:
+		return "catch-all"
+	default:
+		return "NodeKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Error implements the error's Error.
+func (c *Conflict) Error() string {
+	existing := "(" + c.Method + ") " + c.Pattern
+	if c.Pattern == "" {
+		existing = "(" + c.Method + ") handler"
+	}
+
+	if c.Segment < 0 {
+		return c.err.Error() + ": conflicts with " + existing
+	}
+
+	return c.err.Error() + ": " + c.Kind.String() + " segment " + strconv.Itoa(c.Segment) +
+		" conflicts with " + c.ExistingKind.String() + " segment of " + existing
+}
+
+// Unwrap implements the error's Unwrap.
+func (c *Conflict) Unwrap() error {
+	return c.err
+}
+
+// segmentConflict returns the conflict of segs which are rejected by ErrMultiplePathParam in t.
+// The segment index is relative to segs.
+func (mux *ServeMux) segmentConflict(t *tree, segs []Segment) *Conflict {
+	curr := t.root
+
+	for i, seg := range segs {
+		kind, key := seg.Kind, seg.Value
+		if kind == ParamNode {
+			key = typeToken
+		}
+
+		child, ok := curr.Children[key]
+		if ok && child.tid == int(kind) && (kind != ParamNode || child.conv == mux.converters[seg.Value[1:]]) {
+			curr = child
+			continue
+		}
+
+		if !ok {
+			switch kind {
+			case ParamNode:
+				child = curr.find(other)
+			case StaticNode:
+				child = curr.find(param)
+			}
+		}
+
+		if child == nil {
+			break
+		}
+
+		c := &Conflict{Segment: i, Kind: kind, ExistingKind: NodeKind(child.tid), err: ErrMultiplePathParam}
+
+		if rts := child.routes(); len(rts) != 0 {
+			c.Method, c.Pattern = rts[0].method, rts[0].pattern
+		}
+
+		return c
+	}
+
+	return &Conflict{Segment: -1, err: ErrMultiplePathParam}
+}
+
+// duplicateConflict returns the conflict of the method handler h which rejects the new route by ErrDuplicate.
+func duplicateConflict(h http.Handler, method string) *Conflict {
+	c := &Conflict{Method: method, Segment: -1, err: ErrDuplicate}
+
+	if cs, ok := h.(candidates); ok {
+		for _, rt := range cs {
+			if len(rt.matchers) == 0 {
+				c.Pattern = rt.pattern
+				break
+			}
+		}
+	}
+
+	return c
+}
@@ -0,0 +1,95 @@
+package mixer
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestServeMuxErrorConflict(t *testing.T) {
+	mux := New()
+	mux.Get("/catalog/:int/items", TestHandler("items"))
+	mux.Get("/files/new", TestHandler("new"))
+	mux.Get(":str.example.com/", TestHandler("tenant"))
+	mux.Get("/users/", TestHandler("users"), WithMatchers(HeaderMatcher("X-Version", "2")))
+	mux.Get("/users/", TestHandler("users"))
+
+	cases := []struct {
+		name    string
+		method  string
+		pattern string
+		want    *Conflict
+		msg     string
+	}{
+		{
+			name:    "param vs param",
+			method:  http.MethodPut,
+			pattern: "/catalog/:str",
+			want: &Conflict{
+				Method: http.MethodGet, Pattern: "/catalog/:int/items",
+				Segment: 1, Kind: ParamNode, ExistingKind: ParamNode, err: ErrMultiplePathParam,
+			},
+			msg: "httpmux: handler (PUT) /catalog/:str error: multiple types for path param: " +
+				"param segment 1 conflicts with param segment of (GET) /catalog/:int/items",
+		},
+		{
+			name:    "param vs static",
+			method:  http.MethodGet,
+			pattern: "/files/:str/",
+			want: &Conflict{
+				Method: http.MethodGet, Pattern: "/files/new",
+				Segment: 1, Kind: ParamNode, ExistingKind: StaticNode, err: ErrMultiplePathParam,
+			},
+			msg: "httpmux: handler (GET) /files/:str/ error: multiple types for path param: " +
+				"param segment 1 conflicts with static segment of (GET) /files/new",
+		},
+		{
+			name:    "static vs param",
+			method:  http.MethodPost,
+			pattern: "api.example.com/",
+			want: &Conflict{
+				Method: http.MethodGet, Pattern: ":str.example.com/",
+				Segment: 0, Kind: StaticNode, ExistingKind: ParamNode, err: ErrMultiplePathParam,
+			},
+			msg: "httpmux: handler (POST) api.example.com/ error: multiple types for path param: " +
+				"static segment 0 conflicts with param segment of (GET) :str.example.com/",
+		},
+		{
+			name:    "duplicate",
+			method:  http.MethodGet,
+			pattern: "/users/",
+			want:    &Conflict{Method: http.MethodGet, Pattern: "/users/", Segment: -1, err: ErrDuplicate},
+			msg:     "httpmux: handler (GET) /users/ error: duplicate handler: conflicts with (GET) /users/",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := mux.TryHandle(c.method, c.pattern, TestHandler("new"))
+
+			as := Assert{t}
+			as.StrEqual(err.Error(), c.msg, "ServeMux.TryHandle() message")
+
+			var se *ServeMuxError
+
+			as.BoolEqual(errors.As(err, &se), true, "errors.As() ServeMuxError")
+			as.StrEqual(se.Method(), c.method, "ServeMuxError.Method()")
+			as.StrEqual(se.Pattern(), c.pattern, "ServeMuxError.Pattern()")
+			as.Equal(se.Conflict(), c.want, "ServeMuxError.Conflict()")
+			as.BoolEqual(errors.Is(err, c.want.err), true, "errors.Is() cause")
+		})
+	}
+
+	err := mux.TryHandle(http.MethodGet, "/a/:mem", TestHandler("mem"))
+
+	as := Assert{t}
+	as.Equal(err.(*ServeMuxError).Conflict(), (*Conflict)(nil), "ServeMuxError.Conflict() without conflict")
+}
+
+func TestNodeKindString(t *testing.T) {
+	as := Assert{t}
+	as.StrEqual(StaticNode.String(), "static", "StaticNode.String()")
+	as.StrEqual(ParamNode.String(), "param", "ParamNode.String()")
+	as.StrEqual(SlashNode.String(), "slash", "SlashNode.String()")
+	as.StrEqual(NodeKind(root).String(), "NodeKind(3)", "NodeKind.String() unknown")
+}
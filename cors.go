@@ -0,0 +1,194 @@
+package mixer
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS represents the Cross-Origin Resource Sharing policy.
+type CORS struct {
+	// Origins is the allowed origins, `*` allows any origin.
+	Origins []string
+
+	// Headers is the allowed request headers, `*` allows any header.
+	Headers []string
+
+	// ExposeHeaders is the response headers available for the client scripts.
+	ExposeHeaders []string
+
+	// Credentials allows the requests with cookies and authorization headers.
+	Credentials bool
+
+	// MaxAge is how long the preflight response can be cached, zero means not set.
+	MaxAge time.Duration
+}
+
+// WithCORS sets the router-wide CORS policy. It is used for the routes
+// without own policy (see WithRouteCORS) and for the not matched requests.
+// The preflight requests are answered using the methods registered for the path,
+// so the OPTIONS handlers should not be registered by hand.
+func WithCORS(c CORS) Option {
+	return func(mux *ServeMux) {
+		mux.cors = &c
+	}
+}
+
+// WithRouteCORS sets the CORS policy of the route, e.g. for all routes of the Group.
+func WithRouteCORS(c CORS) RouteOption {
+	return func(rt *route) {
+		rt.cors = &c
+	}
+}
+
+// corsHandler returns the handler answering the preflight request
+// or h decorated by the CORS policy of the matched route.
+// If there is no policy for the request h is returned.
+func (mux *ServeMux) corsHandler(h http.Handler, r *http.Request, m Match) http.Handler {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return h
+	}
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	if r.Method == http.MethodOptions && method != "" && m.node != nil {
+		var rt *route
+
+		if cs, ok := m.node.Methods[method].(candidates); ok {
+			rt = cs[0]
+
+			for _, c := range cs {
+				if c.cors != nil {
+					rt = c
+					break
+				}
+			}
+		}
+
+		if c := mux.policy(rt); c != nil {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c.preflight(w, r, m.Methods)
+			})
+		}
+
+		return h
+	}
+
+	c := mux.policy(m.route)
+	if c == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.decorate(w.Header(), origin)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// policy returns the CORS policy of rt or router-wide one.
+func (mux *ServeMux) policy(rt *route) *CORS {
+	if rt != nil && rt.cors != nil {
+		return rt.cors
+	}
+
+	return mux.cors
+}
+
+// preflight answers the preflight request, the allowed methods are the given ones.
+// If the request is not allowed the response has no CORS headers.
+func (c *CORS) preflight(w http.ResponseWriter, r *http.Request, methods []string) {
+	h := w.Header()
+	h.Add("Vary", "Origin")
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	origin, ok := c.allowOrigin(r.Header.Get("Origin"))
+	headers, allowed := c.allowHeaders(r.Header.Get("Access-Control-Request-Headers"))
+
+	if ok && allowed && contains(methods, r.Header.Get("Access-Control-Request-Method")) {
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+		if headers != "" {
+			h.Set("Access-Control-Allow-Headers", headers)
+		}
+
+		if c.Credentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if c.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decorate sets the CORS headers of the actual response for the request from origin.
+func (c *CORS) decorate(h http.Header, origin string) {
+	h.Add("Vary", "Origin")
+
+	origin, ok := c.allowOrigin(origin)
+	if !ok {
+		return
+	}
+
+	h.Set("Access-Control-Allow-Origin", origin)
+
+	if c.Credentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if len(c.ExposeHeaders) != 0 {
+		h.Set("Access-Control-Expose-Headers", strings.Join(c.ExposeHeaders, ", "))
+	}
+}
+
+// allowOrigin returns the value of Access-Control-Allow-Origin header for origin.
+// The wildcard is replaced by origin if the credentials are allowed.
+func (c *CORS) allowOrigin(origin string) (string, bool) {
+	for _, o := range c.Origins {
+		switch {
+		case o == "*" && c.Credentials:
+			return origin, true
+		case o == "*":
+			return o, true
+		case strings.EqualFold(o, origin):
+			return origin, true
+		}
+	}
+
+	return "", false
+}
+
+// allowHeaders returns the value of Access-Control-Allow-Headers header
+// for the comma-separated requested headers, all of them must be allowed.
+func (c *CORS) allowHeaders(requested string) (string, bool) {
+	if requested == "" {
+		return "", true
+	}
+
+	if contains(c.Headers, "*") {
+		return requested, true
+	}
+
+	for _, name := range strings.Split(requested, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+
+		for _, h := range c.Headers {
+			if strings.EqualFold(h, name) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return "", false
+		}
+	}
+
+	return strings.Join(c.Headers, ", "), true
+}
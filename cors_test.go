@@ -0,0 +1,204 @@
+package mixer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORSAllowOrigin(t *testing.T) {
+	cases := []struct {
+		name   string
+		cors   CORS
+		origin string
+		want   string
+		ok     bool
+	}{
+		{
+			name:   "wildcard",
+			cors:   CORS{Origins: []string{"*"}},
+			origin: "https://a.org",
+			want:   "*",
+			ok:     true,
+		},
+		{
+			name:   "wildcard with credentials",
+			cors:   CORS{Origins: []string{"*"}, Credentials: true},
+			origin: "https://a.org",
+			want:   "https://a.org",
+			ok:     true,
+		},
+		{
+			name:   "exact",
+			cors:   CORS{Origins: []string{"https://b.org", "https://A.org"}},
+			origin: "https://a.org",
+			want:   "https://a.org",
+			ok:     true,
+		},
+		{
+			name:   "not allowed",
+			cors:   CORS{Origins: []string{"https://b.org"}},
+			origin: "https://a.org",
+			want:   "",
+			ok:     false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := c.cors.allowOrigin(c.origin)
+
+			as := Assert{t}
+			as.StrEqual(got, c.want, "CORS.allowOrigin() got")
+			as.BoolEqual(ok, c.ok, "CORS.allowOrigin() ok")
+		})
+	}
+}
+
+func TestCORSAllowHeaders(t *testing.T) {
+	c := &CORS{Headers: []string{"Content-Type", "X-Token"}}
+
+	as := Assert{t}
+
+	got, ok := c.allowHeaders("")
+	as.StrEqual(got, "", "empty")
+	as.BoolEqual(ok, true, "empty ok")
+
+	got, ok = c.allowHeaders("content-type, x-token")
+	as.StrEqual(got, "Content-Type, X-Token", "allowed")
+	as.BoolEqual(ok, true, "allowed ok")
+
+	got, ok = c.allowHeaders("content-type, x-other")
+	as.StrEqual(got, "", "not allowed")
+	as.BoolEqual(ok, false, "not allowed ok")
+
+	got, ok = (&CORS{Headers: []string{"*"}}).allowHeaders("x-other")
+	as.StrEqual(got, "x-other", "wildcard")
+	as.BoolEqual(ok, true, "wildcard ok")
+}
+
+func TestServeMuxCORS(t *testing.T) {
+	mux := New(WithCORS(CORS{Origins: []string{"https://a.org"}, ExposeHeaders: []string{"X-Total"}}))
+	mux.Get("/a", TestHandler("a"))
+	mux.Delete("/a", TestHandler("delete"))
+
+	admin := mux.Group("/admin", WithRouteCORS(CORS{
+		Origins:     []string{"https://admin.org"},
+		Headers:     []string{"Authorization"},
+		Credentials: true,
+		MaxAge:      time.Hour,
+	}))
+	admin.Get("/b", TestHandler("b"))
+	admin.Put("/b", TestHandler("put"))
+
+	cases := []struct {
+		name    string
+		method  string
+		url     string
+		header  http.Header
+		code    int
+		body    string
+		allowed http.Header
+	}{
+		{
+			name:   "preflight router policy",
+			method: http.MethodOptions,
+			url:    "/a",
+			header: http.Header{"Origin": {"https://a.org"}, "Access-Control-Request-Method": {"DELETE"}},
+			code:   http.StatusNoContent,
+			allowed: http.Header{
+				"Access-Control-Allow-Origin":  {"https://a.org"},
+				"Access-Control-Allow-Methods": {"DELETE, GET"},
+				"Vary":                         {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+			},
+		},
+		{
+			name:   "preflight group policy",
+			method: http.MethodOptions,
+			url:    "/admin/b",
+			header: http.Header{
+				"Origin":                         {"https://admin.org"},
+				"Access-Control-Request-Method":  {"PUT"},
+				"Access-Control-Request-Headers": {"authorization"},
+			},
+			code: http.StatusNoContent,
+			allowed: http.Header{
+				"Access-Control-Allow-Origin":      {"https://admin.org"},
+				"Access-Control-Allow-Methods":     {"GET, PUT"},
+				"Access-Control-Allow-Headers":     {"Authorization"},
+				"Access-Control-Allow-Credentials": {"true"},
+				"Access-Control-Max-Age":           {"3600"},
+				"Vary":                             {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+			},
+		},
+		{
+			name:    "preflight not allowed method",
+			method:  http.MethodOptions,
+			url:     "/a",
+			header:  http.Header{"Origin": {"https://a.org"}, "Access-Control-Request-Method": {"PUT"}},
+			code:    http.StatusNoContent,
+			allowed: http.Header{"Vary": {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}},
+		},
+		{
+			name:   "preflight not found",
+			method: http.MethodOptions,
+			url:    "/c",
+			header: http.Header{"Origin": {"https://a.org"}, "Access-Control-Request-Method": {"GET"}},
+			code:   http.StatusNotFound,
+			body:   "404 page not found\n",
+			allowed: http.Header{
+				"Access-Control-Allow-Origin":   {"https://a.org"},
+				"Access-Control-Expose-Headers": {"X-Total"},
+				"Vary":                          {"Origin"},
+			},
+		},
+		{
+			name:   "actual router policy",
+			method: http.MethodGet,
+			url:    "/a",
+			header: http.Header{"Origin": {"https://a.org"}},
+			code:   http.StatusOK,
+			allowed: http.Header{
+				"Access-Control-Allow-Origin":   {"https://a.org"},
+				"Access-Control-Expose-Headers": {"X-Total"},
+				"Vary":                          {"Origin"},
+			},
+		},
+		{
+			name:    "actual group policy not allowed origin",
+			method:  http.MethodGet,
+			url:     "/admin/b",
+			header:  http.Header{"Origin": {"https://a.org"}},
+			code:    http.StatusOK,
+			allowed: http.Header{"Vary": {"Origin"}},
+		},
+		{
+			name:    "same origin",
+			method:  http.MethodGet,
+			url:     "/a",
+			header:  http.Header{},
+			code:    http.StatusOK,
+			allowed: http.Header{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := mustReq(http.NewRequest(c.method, c.url, nil))
+			req.Header = c.header
+			w := httptest.NewRecorder()
+
+			mux.ServeHTTP(w, req)
+
+			got := w.Header()
+			got.Del("Content-Type")
+			got.Del("X-Content-Type-Options")
+
+			as := Assert{t}
+			as.IntEqual(w.Code, c.code, "ServeMux.ServeHTTP() code")
+			as.StrEqual(w.Body.String(), c.body, "ServeMux.ServeHTTP() body")
+			as.Equal(got, c.allowed, "ServeMux.ServeHTTP() headers")
+		})
+	}
+}
@@ -12,7 +12,7 @@ type apiHandler struct{}
 
 func (apiHandler) ServeHTTP(http.ResponseWriter, *http.Request) {}
 
-func ExampleServeMuxHandle() {
+func ExampleServeMux_Handle() {
 	mux := New()
 	mux.Handle(http.MethodGet, "/api/v1", apiHandler{})
 	mux.HandleFunc(http.MethodGet, "/", func(w http.ResponseWriter, r *http.Request) {
@@ -83,7 +83,7 @@ func (c *Catalog) Retrieve(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func ExampleServeMuxUsage() {
+func ExampleServeMux_usage() {
 	catalog := Catalog{
 		mu: &sync.RWMutex{},
 		items: map[int]*Item{
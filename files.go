@@ -0,0 +1,123 @@
+package mixer
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// indexFile is the file served for the directory.
+const indexFile = "index.html"
+
+// fileHandler represents the handler serving the files of the file system
+// by the path in the catch-all path param.
+type fileHandler struct {
+	fs    http.FileSystem
+	param int // index of the catch-all path param
+}
+
+// ServeFiles registers the GET and HEAD handlers serving the files of fs under prefix,
+// e.g. `/assets/` or `/tenants/:int/assets/`. The prefix must end with `/`, the rest
+// of the path is matched by the catch-all `*` part and is the last path param, so the
+// other routes under prefix have priority. The paths with `..` parts are rejected,
+// the directories are served by their index.html without listing and the files
+// by http.ServeContent with ETag, Last-Modified and Range support.
+// Because it is an initialization moment will be panics in any error like Handle.
+func (mux *ServeMux) ServeFiles(prefix string, fs http.FileSystem, opts ...RouteOption) {
+	pattern := prefix + catchAllToken
+
+	if !strings.HasSuffix(prefix, pathToken) {
+		panic(patternError(http.MethodGet, pattern))
+	}
+
+	p, err := mux.ParsePattern(pattern)
+	if err != nil {
+		panic(&ServeMuxError{http.MethodGet, pattern, err})
+	}
+
+	h := &fileHandler{fs: fs}
+
+	for _, s := range append(p.Host, p.Path...) {
+		if s.Kind == ParamNode {
+			h.param++
+		}
+	}
+
+	err = mux.Register(
+		Registration{http.MethodGet, pattern, h, opts},
+		Registration{http.MethodHead, pattern, h, opts},
+	)
+	if err != nil {
+		panic(err.(ServeMuxErrors)[0])
+	}
+}
+
+// ServeHTTP implements a Handler's interface.
+func (h *fileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, _ := GetPathParams(r)[h.param].(string)
+
+	for _, part := range strings.Split(name, pathToken) {
+		if part == ".." {
+			http.Error(w, "invalid URL path", http.StatusBadRequest)
+			return
+		}
+	}
+
+	f, err := h.fs.Open(path.Clean(pathToken + name))
+	if err != nil {
+		serveFileError(w, r, err)
+		return
+	}
+	defer f.Close()
+
+	d, err := f.Stat()
+	if err != nil {
+		serveFileError(w, r, err)
+		return
+	}
+
+	slashed := name == "" || strings.HasSuffix(name, pathToken)
+
+	switch {
+	case d.IsDir() && !slashed:
+		redirectHandler(r, r.URL.Path+pathToken).ServeHTTP(w, r)
+		return
+	case !d.IsDir() && slashed:
+		redirectHandler(r, strings.TrimSuffix(r.URL.Path, pathToken)).ServeHTTP(w, r)
+		return
+	case d.IsDir():
+		index, err := h.fs.Open(path.Join(pathToken, name, indexFile))
+		if err != nil {
+			serveFileError(w, r, err)
+			return
+		}
+		defer index.Close()
+
+		if d, err = index.Stat(); err != nil || d.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+
+		f = index
+	}
+
+	if w.Header().Get("ETag") == "" {
+		w.Header().Set("ETag", fmt.Sprintf(`W/"%x-%x"`, d.ModTime().UnixNano(), d.Size()))
+	}
+
+	http.ServeContent(w, r, d.Name(), d.ModTime(), f)
+}
+
+// serveFileError replies to the request with the status code of the file system error.
+func serveFileError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case os.IsNotExist(err):
+		http.NotFound(w, r)
+	case os.IsPermission(err):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,190 @@
+package mixer
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServeMuxServeFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mixer")
+	must(err)
+
+	defer os.RemoveAll(dir)
+
+	must(os.MkdirAll(filepath.Join(dir, "css"), 0o700))
+	must(os.MkdirAll(filepath.Join(dir, "empty"), 0o700))
+	must(ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>"), 0o600))
+	must(ioutil.WriteFile(filepath.Join(dir, "app.js"), []byte("console.log()"), 0o600))
+	must(ioutil.WriteFile(filepath.Join(dir, "css", "index.html"), []byte("<css>"), 0o600))
+
+	mux := New()
+	mux.ServeFiles("/assets/", http.Dir(dir))
+	mux.ServeFiles("/tenants/:int/assets/", http.Dir(dir))
+	mux.Get("/assets/health", TestHandler("health"), WithName("health"))
+
+	cases := []struct {
+		name     string
+		method   string
+		url      string
+		header   http.Header
+		code     int
+		body     string
+		location string
+	}{
+		{name: "file", method: http.MethodGet, url: "/assets/app.js", code: http.StatusOK, body: "console.log()"},
+		{name: "head", method: http.MethodHead, url: "/assets/app.js", code: http.StatusOK},
+		{name: "root index", method: http.MethodGet, url: "/assets/", code: http.StatusOK, body: "<html>"},
+		{name: "dir index", method: http.MethodGet, url: "/assets/css/", code: http.StatusOK, body: "<css>"},
+		{
+			name: "dir redirect", method: http.MethodGet, url: "/assets/css?v=1",
+			code: http.StatusMovedPermanently, location: "/assets/css/?v=1",
+		},
+		{
+			name: "file redirect", method: http.MethodGet, url: "/assets/app.js/",
+			code: http.StatusMovedPermanently, location: "/assets/app.js",
+		},
+		{name: "dir without index", method: http.MethodGet, url: "/assets/empty/", code: http.StatusNotFound},
+		{name: "missing", method: http.MethodGet, url: "/assets/missing.js", code: http.StatusNotFound},
+		{name: "traversal", method: http.MethodGet, url: "/assets/css/%2e%2e/%2e%2e/secret", code: http.StatusBadRequest},
+		{
+			name: "range", method: http.MethodGet, url: "/assets/app.js",
+			header: http.Header{"Range": {"bytes=0-6"}}, code: http.StatusPartialContent, body: "console",
+		},
+		{
+			name: "typed prefix", method: http.MethodGet, url: "/tenants/1/assets/app.js",
+			code: http.StatusOK, body: "console.log()",
+		},
+		{name: "typed prefix mismatch", method: http.MethodGet, url: "/tenants/a/assets/app.js", code: http.StatusNotFound},
+		{name: "static route has priority", method: http.MethodGet, url: "/assets/health", code: http.StatusOK},
+		{name: "method", method: http.MethodPost, url: "/assets/app.js", code: http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(c.method, c.url, nil)
+			for k, v := range c.header {
+				r.Header[k] = v
+			}
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			as := Assert{t}
+			as.IntEqual(w.Code, c.code, "ServeMux.ServeFiles() code")
+			as.StrEqual(w.Header().Get("Location"), c.location, "ServeMux.ServeFiles() location")
+
+			if c.body != "" {
+				as.StrEqual(w.Body.String(), c.body, "ServeMux.ServeFiles() body")
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/assets/app.js", nil))
+
+	etag, modified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+
+	as := Assert{t}
+	as.BoolEqual(etag != "" && modified != "", true, "ServeMux.ServeFiles() validators")
+
+	r := httptest.NewRequest(http.MethodGet, "/assets/app.js", nil)
+	r.Header.Set("If-None-Match", etag)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	as.IntEqual(w.Code, http.StatusNotModified, "ServeMux.ServeFiles() If-None-Match")
+}
+
+func TestServeMuxServeFilesCaseInsensitive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mixer")
+	must(err)
+
+	defer os.RemoveAll(dir)
+
+	must(ioutil.WriteFile(filepath.Join(dir, "app.js"), []byte("console.log()"), 0o600))
+
+	mux := New(WithCaseInsensitive(true))
+	mux.ServeFiles("/assets/", http.Dir(dir))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, mustReq(http.NewRequest(http.MethodGet, "/assets/app.js", nil)))
+
+	as := Assert{t}
+	as.IntEqual(w.Code, http.StatusOK, "canonical code")
+	as.StrEqual(w.Body.String(), "console.log()", "canonical body")
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, mustReq(http.NewRequest(http.MethodGet, "/Assets/app.js", nil)))
+
+	as.IntEqual(w.Code, http.StatusMovedPermanently, "redirect code")
+	as.StrEqual(w.Header().Get("Location"), "/assets/app.js", "redirect location")
+}
+
+func TestServeMuxServeFilesPanics(t *testing.T) {
+	cases := []struct {
+		name   string
+		prefix string
+		err    error
+	}{
+		{name: "no trailing slash", prefix: "/assets", err: ErrPattern},
+		{name: "invalid prefix", prefix: "/assets/:mem/", err: ErrPathParam},
+		{name: "duplicate", prefix: "/static/", err: ErrDuplicate},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mux := New()
+			mux.Get("/static/*", TestHandler("static"))
+
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, c.err) {
+					t.Errorf("ServeMux.ServeFiles() panic = %v, want = %v", err, c.err)
+				}
+			}()
+
+			mux.ServeFiles(c.prefix, http.Dir("."))
+		})
+	}
+}
+
+func TestServeMuxMatchCatchAll(t *testing.T) {
+	mux := New()
+	mux.Get("/files/*", TestHandler("files"))
+	mux.Get("/files/:int/meta", TestHandler("meta"))
+	mux.Get("/files/:int/*", TestHandler("file"))
+
+	cases := []struct {
+		url     string
+		pattern string
+		params  PathParams
+	}{
+		{url: "/files/", pattern: "/files/*", params: PathParams{0: ""}},
+		{url: "/files/a/b/", pattern: "/files/*", params: PathParams{0: "a/b/"}},
+		{url: "/files/1/meta", pattern: "/files/:int/meta", params: PathParams{0: 1}},
+		{url: "/files/1/x/y", pattern: "/files/:int/*", params: PathParams{0: 1, 1: "x/y"}},
+		{url: "/files/a/meta", pattern: "/files/*", params: PathParams{0: "a/meta"}},
+		{url: "/files/a%2Fb", pattern: "/files/*", params: PathParams{0: "a/b"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			m, err := mux.Match(httptest.NewRequest(http.MethodGet, c.url, nil))
+
+			as := Assert{t}
+			as.Equal(err, nil, "ServeMux.Match() error")
+			as.StrEqual(m.Pattern, c.pattern, "ServeMux.Match() pattern")
+			as.Equal(m.Params, c.params, "ServeMux.Match() params")
+		})
+	}
+
+	_, err := mux.Match(httptest.NewRequest(http.MethodGet, "/files", nil))
+
+	as := Assert{t}
+	as.BoolEqual(errors.Is(err, ErrNotFound), true, "ServeMux.Match() without slash")
+}
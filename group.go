@@ -0,0 +1,163 @@
+package mixer
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Group represents the set of routes with the common pattern prefix and options.
+type Group struct {
+	mux    *ServeMux
+	prefix string
+	opts   []RouteOption
+}
+
+// Group returns the group of routes which patterns start with prefix.
+// The options are applied to every route of the group before the route ones.
+// The prefix can contain the host and path params, e.g. `api.example.org/v1/:int`.
+func (mux *ServeMux) Group(prefix string, opts ...RouteOption) *Group {
+	return &Group{mux: mux, prefix: strings.TrimSuffix(prefix, "/"), opts: opts}
+}
+
+// Group returns the nested group which inherits prefix and options of g.
+func (g *Group) Group(prefix string, opts ...RouteOption) *Group {
+	return &Group{
+		mux:    g.mux,
+		prefix: g.prefix + strings.TrimSuffix(prefix, "/"),
+		opts:   append(g.options(), opts...),
+	}
+}
+
+// Handle registers the handler for the given method and pattern within the group.
+func (g *Group) Handle(method, pattern string, handler http.Handler, opts ...RouteOption) {
+	g.mux.Handle(method, g.prefix+pattern, handler, append(g.options(), opts...)...)
+}
+
+// TryHandle is like Handle but returns the error instead of panic.
+func (g *Group) TryHandle(method, pattern string, handler http.Handler, opts ...RouteOption) error {
+	return g.mux.TryHandle(method, g.prefix+pattern, handler, append(g.options(), opts...)...)
+}
+
+// HandleFunc registers the handler function for the given method and pattern within the group.
+func (g *Group) HandleFunc(
+	method, pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	if handler == nil {
+		panic(handlerError(method, g.prefix+pattern))
+	}
+
+	g.Handle(method, pattern, http.HandlerFunc(handler), opts...)
+}
+
+// Get registers the GET handler for the given pattern within the group.
+func (g *Group) Get(pattern string, handler http.Handler, opts ...RouteOption) {
+	g.Handle(http.MethodGet, pattern, handler, opts...)
+}
+
+// Head registers the HEAD handler for the given pattern within the group.
+func (g *Group) Head(pattern string, handler http.Handler, opts ...RouteOption) {
+	g.Handle(http.MethodHead, pattern, handler, opts...)
+}
+
+// Post registers the POST handler for the given pattern within the group.
+func (g *Group) Post(pattern string, handler http.Handler, opts ...RouteOption) {
+	g.Handle(http.MethodPost, pattern, handler, opts...)
+}
+
+// Put registers the PUT handler for the given pattern within the group.
+func (g *Group) Put(pattern string, handler http.Handler, opts ...RouteOption) {
+	g.Handle(http.MethodPut, pattern, handler, opts...)
+}
+
+// Patch registers the PATCH handler for the given pattern within the group.
+func (g *Group) Patch(pattern string, handler http.Handler, opts ...RouteOption) {
+	g.Handle(http.MethodPatch, pattern, handler, opts...)
+}
+
+// Delete registers the DELETE handler for the given pattern within the group.
+func (g *Group) Delete(pattern string, handler http.Handler, opts ...RouteOption) {
+	g.Handle(http.MethodDelete, pattern, handler, opts...)
+}
+
+// Connect registers the CONNECT handler for the given pattern within the group.
+func (g *Group) Connect(pattern string, handler http.Handler, opts ...RouteOption) {
+	g.Handle(http.MethodConnect, pattern, handler, opts...)
+}
+
+// Options registers the OPTIONS handler for the given pattern within the group.
+func (g *Group) Options(pattern string, handler http.Handler, opts ...RouteOption) {
+	g.Handle(http.MethodOptions, pattern, handler, opts...)
+}
+
+// Trace registers the TRACE handler for the given pattern within the group.
+func (g *Group) Trace(pattern string, handler http.Handler, opts ...RouteOption) {
+	g.Handle(http.MethodTrace, pattern, handler, opts...)
+}
+
+// GetFunc registers the GET handler function for the given pattern within the group.
+func (g *Group) GetFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	g.HandleFunc(http.MethodGet, pattern, handler, opts...)
+}
+
+// HeadFunc registers the HEAD handler function for the given pattern within the group.
+func (g *Group) HeadFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	g.HandleFunc(http.MethodHead, pattern, handler, opts...)
+}
+
+// PostFunc registers the POST handler function for the given pattern within the group.
+func (g *Group) PostFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	g.HandleFunc(http.MethodPost, pattern, handler, opts...)
+}
+
+// PutFunc registers the PUT handler function for the given pattern within the group.
+func (g *Group) PutFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	g.HandleFunc(http.MethodPut, pattern, handler, opts...)
+}
+
+// PatchFunc registers the PATCH handler function for the given pattern within the group.
+func (g *Group) PatchFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	g.HandleFunc(http.MethodPatch, pattern, handler, opts...)
+}
+
+// DeleteFunc registers the DELETE handler function for the given pattern within the group.
+func (g *Group) DeleteFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	g.HandleFunc(http.MethodDelete, pattern, handler, opts...)
+}
+
+// ConnectFunc registers the CONNECT handler function for the given pattern within the group.
+func (g *Group) ConnectFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	g.HandleFunc(http.MethodConnect, pattern, handler, opts...)
+}
+
+// OptionsFunc registers the OPTIONS handler function for the given pattern within the group.
+func (g *Group) OptionsFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	g.HandleFunc(http.MethodOptions, pattern, handler, opts...)
+}
+
+// TraceFunc registers the TRACE handler function for the given pattern within the group.
+func (g *Group) TraceFunc(
+	pattern string, handler func(http.ResponseWriter, *http.Request), opts ...RouteOption,
+) {
+	g.HandleFunc(http.MethodTrace, pattern, handler, opts...)
+}
+
+// options returns the copy of the group options, so appending to it never modifies the group.
+func (g *Group) options() []RouteOption {
+	return append([]RouteOption(nil), g.opts...)
+}
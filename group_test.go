@@ -0,0 +1,116 @@
+package mixer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServeMuxGroup(t *testing.T) {
+	var (
+		order []string
+		route *Route
+	)
+
+	mw := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				route = GetRoute(r)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	mux := New()
+	api := mux.Group("/api/", WithMiddleware(mw("api")))
+	v1 := api.Group("/v1", WithMiddleware(mw("v1")))
+
+	api.Get("/", TestHandler("root"))
+	v1.Get("/users/:int", TestHandler("user"), WithName("user"), WithMiddleware(mw("user")))
+	v1.Post("/users", TestHandler("create"))
+
+	cases := []struct {
+		method string
+		url    string
+		route  *Route
+		order  []string
+	}{
+		{
+			method: http.MethodGet,
+			url:    "/api/",
+			route:  &Route{Method: http.MethodGet, Pattern: "/api/"},
+			order:  []string{"api"},
+		},
+		{
+			method: http.MethodGet,
+			url:    "/api/v1/users/1",
+			route:  &Route{Method: http.MethodGet, Pattern: "/api/v1/users/:int", Name: "user"},
+			order:  []string{"api", "v1", "user"},
+		},
+		{
+			method: http.MethodPost,
+			url:    "/api/v1/users",
+			route:  &Route{Method: http.MethodPost, Pattern: "/api/v1/users"},
+			order:  []string{"api", "v1"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			order = nil
+
+			mux.ServeHTTP(httptest.NewRecorder(), mustReq(http.NewRequest(c.method, c.url, nil)))
+
+			route.Handler = nil
+
+			as := Assert{t}
+			as.Equal(route, c.route, "Group() route")
+			as.Equal(order, c.order, "Group() middleware order")
+		})
+	}
+
+	err := v1.TryHandle(http.MethodPost, "/users", TestHandler("create"))
+
+	as := Assert{t}
+	as.IntEqual(len(api.opts), 1, "nested group does not modify parent")
+	as.BoolEqual(errors.Is(err, ErrDuplicate), true, "Group.TryHandle() error")
+}
+
+func TestGroupHandleFunc(t *testing.T) {
+	t.Run("panic on nil handler", func(t *testing.T) {
+		defer func() {
+			as := Assert{t}
+			as.Equal(recover(), handlerError(http.MethodGet, "/api/a"), "Group.HandleFunc() panic")
+		}()
+
+		New().Group("/api").HandleFunc(http.MethodGet, "/a", nil)
+	})
+
+	mux := New()
+	g := mux.Group("/api")
+	fn := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusAccepted) }
+
+	register := map[string]func(string, func(http.ResponseWriter, *http.Request), ...RouteOption){
+		http.MethodGet:     g.GetFunc,
+		http.MethodHead:    g.HeadFunc,
+		http.MethodPost:    g.PostFunc,
+		http.MethodPut:     g.PutFunc,
+		http.MethodPatch:   g.PatchFunc,
+		http.MethodDelete:  g.DeleteFunc,
+		http.MethodConnect: g.ConnectFunc,
+		http.MethodOptions: g.OptionsFunc,
+		http.MethodTrace:   g.TraceFunc,
+	}
+
+	for method, fnc := range register {
+		fnc("/a", fn)
+
+		resp := httptest.NewRecorder()
+		mux.ServeHTTP(resp, mustReq(http.NewRequest(method, "/api/a", nil)))
+
+		as := Assert{t}
+		as.IntEqual(resp.Code, http.StatusAccepted, "Group."+method+" func")
+	}
+}
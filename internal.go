@@ -1,9 +1,15 @@
 package mixer
 
 import (
+	"errors"
+	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type (
@@ -15,6 +21,7 @@ type (
 	}
 
 	// node represents the set of http.Handler and can be "typed".
+	// The nodes of the host tree have no handlers but sub tree of the paths.
 	// Different nodes obey the next rules:
 	// 	   `*` | `:` | `/`  , where `:` - path param, `/` - trailing slash, `*` - other
 	// 	0)  0  |  0  |  0  -> node ready to be set
@@ -22,13 +29,40 @@ type (
 	// 	2)  0  |  1  |  1  -> combination `:` and `/` allowed
 	// 	3)  1  |  0  |  0  -> any combination of `*` per node
 	// 	4)  1  |  0  |  1  -> combination `*` and `/` allowed
+	// The catch-all node (the last `*` part of the pattern) is allowed with any
+	// combination, it matches the rest of the path if no other node matches.
 	node struct {
 		tid      int
 		conv     *convert
+		sub      *tree
 		Methods  map[string]http.Handler `json:"methods"`
 		Children map[string]*node        `json:"children"`
 	}
 
+	// route represents the registered handler with its conditions.
+	route struct {
+		method     string
+		pattern    string
+		name       string
+		handler    http.Handler
+		matchers   []Matcher
+		middleware []Middleware
+		metrics    *metrics
+		cors       *CORS
+		timeout    time.Duration
+		maxBytes   int64
+		cache      *cache
+		summary    string
+	}
+
+	// candidates represents the ordered routes registered for one method of node.
+	// The first route which matchers are satisfied is used.
+	candidates []*route
+
+	// segment represents the function for converting the request part
+	// to the value for path param and the key for static node.
+	segment func(part string) (string, string, error)
+
 	// convert represents the convert function for path params.
 	convert func(string) (interface{}, error)
 
@@ -39,16 +73,23 @@ type (
 )
 
 const (
-	other = iota // other `*`
-	param        // path param `:`
-	slash        // trailing slash `/`
-	root         // only for tree.root node
+	other    = iota // other `*`
+	param           // path param `:`
+	slash           // trailing slash `/`
+	root            // only for tree.root node
+	catchall        // catch-all `*`
 
 	// pathToken determines delimiter for splitting URL parts.
 	pathToken = "/"
 
 	// typeToken determines special token for URL path params.
 	typeToken = ":"
+
+	// hostToken determines delimiter for splitting host labels.
+	hostToken = "."
+
+	// catchAllToken determines the last path part which matches the rest of the path.
+	catchAllToken = "*"
 )
 
 // methodError wraps the ErrMethod error.
@@ -66,16 +107,69 @@ func patternError(m, p string) *ServeMuxError {
 	return &ServeMuxError{m, p, ErrPattern}
 }
 
-// duplicateError wraps the ErrDuplicate error.
-func duplicateError(m, p string) *ServeMuxError {
-	return &ServeMuxError{m, p, ErrDuplicate}
-}
-
 // notFoundError wraps the ErrNotFound error.
 func notFoundError(m, p string) *ServeMuxError {
 	return &ServeMuxError{m, p, ErrNotFound}
 }
 
+// paramError wraps the path param error by the param index.
+func paramError(i int, err error) *ServeMuxError {
+	return &ServeMuxError{"", typeToken + strconv.Itoa(i), err}
+}
+
+// serveError replies to the request with the status code associated with err.
+// It is the default error handler of ServeMux. The recovered panic is logged
+// with its stack like by net/http and 500 is not written if the response has started.
+func serveError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrPanic):
+		var pe *PanicError
+		if errors.As(err, &pe) {
+			log.Printf("%v serving %s\n%s", pe, r.RemoteAddr, pe.Stack)
+		}
+
+		if sw, ok := w.(*statusWriter); ok && sw.code != 0 {
+			return
+		}
+
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	case errors.Is(err, ErrNotAcceptable):
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+	case errors.Is(err, ErrUnsupportedMediaType):
+		http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+	case errors.Is(err, ErrTimeout):
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	case errors.Is(err, ErrBodyTooLarge):
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// redirectHandler returns the handler which redirects the request to the path target.
+// The query is kept and the method is preserved for non-idempotent requests.
+func redirectHandler(r *http.Request, target string) http.Handler {
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+
+	code := http.StatusMovedPermanently
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		code = http.StatusPermanentRedirect
+	}
+
+	return http.RedirectHandler(target, code)
+}
+
+// chain decorates h by mw, the first middleware is the outermost.
+func chain(h http.Handler, mw []Middleware) http.Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+
+	return h
+}
+
 // intConv adapts interface of the type conversion function from string to int.
 func intConv(s string) (interface{}, error) {
 	return strconv.Atoi(s)
@@ -109,6 +203,125 @@ func splitURL(url string) ([]string, error) {
 	return parts, nil
 }
 
+// splitPattern splits pattern to host and path parts.
+// The host is everything before the first pathToken if it starts with typeToken
+// or contains hostToken, otherwise the pattern is the path.
+func splitPattern(pattern string) (string, string) {
+	i := strings.Index(pattern, pathToken)
+	if i < 0 {
+		i = len(pattern)
+	}
+
+	if host := pattern[:i]; strings.HasPrefix(host, typeToken) || strings.Contains(host, hostToken) {
+		return host, pattern[i:]
+	}
+
+	return "", pattern
+}
+
+// splitHost splits host to labels separated by hostToken in reverse order,
+// so the hosts are stored in tree from top-level domain to the subdomains.
+// Static labels are lowered because host is case-insensitive.
+// The empty host returns no labels.
+func splitHost(host string) ([]string, error) {
+	if host == "" {
+		return nil, nil
+	}
+
+	labels := strings.Split(host, hostToken)
+
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+
+	for i, label := range labels {
+		if label == "" {
+			return labels, ErrPattern
+		}
+
+		if !strings.HasPrefix(label, typeToken) {
+			labels[i] = strings.ToLower(label)
+		}
+	}
+
+	return labels, nil
+}
+
+// stripPort returns host without port if any.
+func stripPort(host string) string {
+	h, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return host
+	}
+
+	return h
+}
+
+// addCandidate returns the candidates of h with appended rt.
+// The route can not be added after the route without matchers,
+// because such route matches any request and the next one is unreachable.
+func addCandidate(h http.Handler, rt *route) (candidates, error) {
+	if h == nil {
+		return candidates{rt}, nil
+	}
+
+	cs, ok := h.(candidates)
+	if !ok {
+		return nil, ErrDuplicate
+	}
+
+	for _, c := range cs {
+		if len(c.matchers) == 0 {
+			return nil, ErrDuplicate
+		}
+	}
+
+	// full slice expression prevents changing of the origin candidates
+	return append(cs[:len(cs):len(cs)], rt), nil
+}
+
+// match returns the first route which matchers are satisfied by r.
+// If no one matches the most specific error is returned: ErrUnsupportedMediaType,
+// ErrNotAcceptable and ErrNotFound otherwise.
+func (cs candidates) match(r *http.Request) (*route, error) {
+	reason := ErrNotFound
+
+	for _, rt := range cs {
+		err := rt.match(r)
+		if err == nil {
+			return rt, nil
+		}
+
+		if errors.Is(err, ErrUnsupportedMediaType) || (errors.Is(err, ErrNotAcceptable) && reason == ErrNotFound) {
+			reason = err
+		}
+	}
+
+	return nil, reason
+}
+
+// ServeHTTP implements a Handler's interface.
+func (cs candidates) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt, err := cs.match(r)
+	if err != nil {
+		serveError(w, r, err)
+		return
+	}
+
+	rt.handler.ServeHTTP(w, r)
+}
+
+// match returns the first error of the route matchers or nil if r satisfies all of them.
+func (rt *route) match(r *http.Request) error {
+	for _, m := range rt.matchers {
+		if err := m(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // deepcopy returns full copy of the receiver tree.
 // For conv and Methods stores only links because if
 // insert operation was correct copy can replace origin.
@@ -136,6 +349,94 @@ func (n *node) deepcopy() *node {
 	return c
 }
 
+// methods returns the sorted methods which have handlers.
+func (n *node) methods() []string {
+	methods := make([]string, 0, len(n.Methods))
+
+	for method, h := range n.Methods {
+		if h != nil {
+			methods = append(methods, method)
+		}
+	}
+
+	sort.Strings(methods)
+
+	return methods
+}
+
+// keys returns the sorted keys of the children.
+func (n *node) keys() []string {
+	keys := make([]string, 0, len(n.Children))
+
+	for k := range n.Children {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// trees returns the paths and hosts trees of the ServeMux.
+func (mux *ServeMux) trees() (*tree, *tree) {
+	mux.mu.RLock()
+	defer mux.mu.RUnlock()
+
+	return mux.tree, mux.hosts
+}
+
+// routes returns all registered routes sorted by pattern and method.
+// The candidates of the same method and pattern keep the registration order.
+func (mux *ServeMux) routes() []*route {
+	paths, hosts := mux.trees()
+	routes := append(paths.root.routes(), hosts.root.routes()...)
+	sortRoutes(routes)
+
+	return routes
+}
+
+// routes returns the routes of n and its descendants including the host sub trees
+// sorted like ServeMux.routes.
+func (n *node) routes() []*route {
+	var routes []*route
+
+	n.walk(func(n *node) {
+		for _, h := range n.Methods {
+			if cs, ok := h.(candidates); ok {
+				routes = append(routes, cs...)
+			}
+		}
+
+		if n.sub != nil {
+			routes = append(routes, n.sub.root.routes()...)
+		}
+	})
+
+	sortRoutes(routes)
+
+	return routes
+}
+
+// sortRoutes sorts routes by pattern and method keeping the order of the candidates.
+func sortRoutes(routes []*route) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].pattern != routes[j].pattern {
+			return routes[i].pattern < routes[j].pattern
+		}
+
+		return routes[i].method < routes[j].method
+	})
+}
+
+// walk calls fn for n and all its descendants.
+func (n *node) walk(fn func(*node)) {
+	fn(n)
+
+	for _, c := range n.Children {
+		c.walk(fn)
+	}
+}
+
 // find finds child node by type ID.
 func (n *node) find(tid int) *node {
 	for _, c := range n.Children {
@@ -172,36 +473,37 @@ func (n *node) insert(key string, in *node) bool {
 	return true
 }
 
-// insert builds parts to inner tree by some rules:
-// 	 - if node for part not exist it will be created.
-// 	 - if node for part exist it will be returned.
-// Returns methods associated with last inserted or found node.
-func (mux *ServeMux) insert(parts []string) (map[string]http.Handler, error) {
-	cp := mux.tree.deepcopy()
+// build builds segs to the copy of t by the insert rules, the kind of node is the kind
+// of segment and the value of segment is the key of static node.
+// Returns the copy and its last inserted or found node, t stays untouched.
+func (mux *ServeMux) build(t *tree, segs []Segment) (*tree, *node, error) {
+	cp := t.deepcopy()
 	curr := cp.root
 
-	for _, part := range parts {
-		in := new(node)
+	for _, seg := range segs {
+		in, key := new(node), seg.Value
 
-		switch part[:1] {
-		case pathToken:
+		switch seg.Kind {
+		case CatchAllNode:
+			in.tid = catchall
+		case SlashNode:
 			in.tid = slash
-		case typeToken:
-			conv := mux.converters[part[1:]]
+		case ParamNode:
+			conv := mux.converters[key[len(typeToken):]]
 
 			if conv == nil {
-				return nil, ErrPathParam
+				return nil, nil, ErrPathParam
 			}
 
 			in.tid = param
 			in.conv = conv
 
-			part = typeToken
+			key = typeToken
 		}
 
-		child, ok := curr.Children[part]
-		if ok && child.conv != in.conv {
-			return nil, ErrMultiplePathParam
+		child, ok := curr.Children[key]
+		if ok && (child.tid != in.tid || child.conv != in.conv) {
+			return nil, nil, ErrMultiplePathParam
 		}
 
 		if ok {
@@ -209,18 +511,253 @@ func (mux *ServeMux) insert(parts []string) (map[string]http.Handler, error) {
 			continue
 		}
 
-		if !curr.insert(part, in) {
-			return nil, ErrMultiplePathParam
+		if !curr.insert(key, in) {
+			return nil, nil, ErrMultiplePathParam
 		}
 
 		curr = in
 	}
 
-	if curr.Methods == nil {
-		curr.Methods = make(map[string]http.Handler)
+	return cp, curr, nil
+}
+
+// search searches the node for parts starting from n.
+// If seg is not nil every part except trailing slash is converted by it before matching.
+// The values of path params are added to params after existing ones.
+// If the found node has no handlers the nearest catch-all node on the way is used.
+// Returns nil if node not found.
+func (n *node) search(parts []string, params PathParams, seg segment) *node {
+	var (
+		fallback *node // the catch-all node of the deepest visited node
+		from     int   // the index of the first part matched by fallback
+		count    int   // the number of params before fallback
+	)
+
+	found := n
+
+	for i, part := range parts {
+		if c := found.Children[catchAllToken]; c != nil && c.tid == catchall {
+			fallback, from, count = c, i, len(params)
+		}
+
+		if found = found.next(part, i == len(parts)-1, params, seg); found == nil {
+			break
+		}
+	}
+
+	if (found == nil || len(found.Methods) == 0) && fallback != nil {
+		val, ok := rest(parts[from:], seg)
+		if !ok {
+			return nil
+		}
+
+		for k := range params {
+			if k >= count {
+				delete(params, k)
+			}
+		}
+
+		params[count] = val
+
+		return fallback
+	}
+
+	return found
+}
+
+// next returns the child of n for part or nil if there is no one.
+// The value of path param is added to params.
+func (n *node) next(part string, last bool, params PathParams, seg segment) *node {
+	if part == pathToken && last {
+		return n.Children[pathToken]
+	}
+
+	key := part
+
+	if seg != nil {
+		var err error
+
+		if part, key, err = seg(part); err != nil {
+			return nil
+		}
+	}
+
+	child, ok := n.Children[key]
+	if ok && child.tid == other {
+		return child
+	}
+
+	child, ok = n.Children[typeToken]
+	if !ok {
+		return nil
+	}
+
+	val, err := (*child.conv)(part)
+	if err != nil {
+		return nil
+	}
+
+	params[len(params)] = val
+
+	return child
+}
+
+// rest returns the value of the catch-all path param: the parts converted by seg
+// and joined by pathToken. The trailing slash is kept if there is any other part.
+func rest(parts []string, seg segment) (string, bool) {
+	vals := make([]string, 0, len(parts))
+
+	for i, part := range parts {
+		if part == pathToken && i == len(parts)-1 {
+			if len(vals) != 0 {
+				vals = append(vals, "")
+			}
+
+			break
+		}
+
+		if seg != nil {
+			var err error
+
+			if part, _, err = seg(part); err != nil {
+				return "", false
+			}
+		}
+
+		vals = append(vals, part)
+	}
+
+	return strings.Join(vals, pathToken), true
+}
+
+// segment converts the request part to the value for path param and the key for static node.
+// The part is percent-decoded (if not raw) and normalized, the key is folded in addition.
+// The escaped pathToken inside part is a data rather than a separator.
+func (mux *ServeMux) segment(part string) (string, string, error) {
+	if !mux.raw {
+		var err error
+
+		if part, err = url.PathUnescape(part); err != nil {
+			return "", "", err
+		}
+	}
+
+	if mux.normalize != nil {
+		part = mux.normalize(part)
+	}
+
+	return part, mux.key(part), nil
+}
+
+// key returns the key of static node for the normalized part.
+func (mux *ServeMux) key(part string) string {
+	if mux.fold {
+		return strings.ToLower(part)
 	}
 
-	*mux.tree = *cp
+	return part
+}
+
+// keys replaces the values of the static segments by the keys of static nodes.
+func (mux *ServeMux) keys(segs []Segment) {
+	for i, seg := range segs {
+		if seg.Kind == StaticNode {
+			segs[i].Value = mux.key(mux.static(seg.Value))
+		}
+	}
+}
+
+// static returns the static part of the pattern percent-decoded (if not raw) and normalized.
+// The escapes are validated by ParsePattern.
+func (mux *ServeMux) static(part string) string {
+	if !mux.raw {
+		part, _ = url.PathUnescape(part)
+	}
+
+	if mux.normalize != nil {
+		part = mux.normalize(part)
+	}
+
+	return part
+}
+
+// canonical returns the URL path in the form of registered pattern for the request parts.
+// The request parts of the path params and the catch-all are kept as is.
+// Returns false if the request path is in canonical form already.
+func (mux *ServeMux) canonical(pattern string, parts []string) (string, bool) {
+	p, err := mux.ParsePattern(pattern)
+	if err != nil {
+		return "", false
+	}
+
+	segs := p.Path
+
+	// the catch-all matches one part at least
+	if len(parts) != len(segs) && (segs[len(segs)-1].Kind != CatchAllNode || len(parts) < len(segs)) {
+		return "", false
+	}
+
+	changed := false
+	out := make([]string, 0, len(parts))
+
+	for i, seg := range segs {
+		switch seg.Kind {
+		case SlashNode:
+			out = append(out, "")
+		case ParamNode:
+			out = append(out, parts[i])
+		case CatchAllNode:
+			for _, part := range parts[i:] {
+				if part == pathToken {
+					part = ""
+				}
+
+				out = append(out, part)
+			}
+		default:
+			static := mux.static(seg.Value)
+
+			got, _, err := mux.segment(parts[i])
+			if err != nil {
+				return "", false
+			}
+
+			changed = changed || got != static
+			out = append(out, url.PathEscape(static))
+		}
+	}
+
+	return pathToken + strings.Join(out, pathToken), changed
+}
+
+// searchHost returns the root node of the paths tree for the request host in hosts.
+// If no host pattern matches the root of the default tree is returned.
+func searchHost(paths, hosts *tree, r *http.Request, params PathParams) *node {
+	if len(hosts.root.Children) == 0 {
+		return paths.root
+	}
+
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+
+	labels, err := splitHost(strings.ToLower(stripPort(host)))
+	if err != nil || len(labels) == 0 {
+		return paths.root
+	}
+
+	found := make(PathParams)
+
+	n := hosts.root.search(labels, found, nil)
+	if n == nil || n.sub == nil {
+		return paths.root
+	}
+
+	// labels were searched in reverse order but params keep the host order
+	for i, v := range found {
+		params[len(found)-1-i] = v
+	}
 
-	return curr.Methods, nil
+	return n.sub.root
 }
@@ -0,0 +1,56 @@
+// Package decl reads the route declarations of Go files for the mixer tools.
+package decl
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/kxnes/mixer"
+)
+
+// Directive returns the route declaration of the Go comment line
+//
+//	//mixer:route METHOD PATTERN HANDLER [NAME]
+//
+// or false if line is not the directive. The Line of the declaration is not set.
+func Directive(line string) (mixer.RouteDecl, bool) {
+	text := strings.TrimSpace(line)
+	if !strings.HasPrefix(text, "//") {
+		return mixer.RouteDecl{}, false
+	}
+
+	f := strings.Fields(text[len("//"):])
+	if len(f) < 4 || len(f) > 5 || f[0] != "mixer:route" {
+		return mixer.RouteDecl{}, false
+	}
+
+	d := mixer.RouteDecl{Method: f[1], Pattern: f[2], Handler: f[3]}
+	if len(f) == 5 {
+		d.Name = f[4]
+	}
+
+	return d, true
+}
+
+// Registration returns the method registered by the ServeMux method name
+// (e.g. GET for Get and GetFunc) and the index of its pattern argument.
+// The method is empty for Handle, HandleFunc and TryHandle which take it as the first argument.
+// Returns false if name is not the registration method.
+func Registration(name string) (string, int, bool) {
+	switch name {
+	case "Handle", "HandleFunc", "TryHandle":
+		return "", 1, true
+	}
+
+	base := strings.TrimSuffix(name, "Func")
+
+	switch method := strings.ToUpper(base); method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		if base == method[:1]+strings.ToLower(method[1:]) {
+			return method, 0, true
+		}
+	}
+
+	return "", 0, false
+}
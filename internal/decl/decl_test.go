@@ -0,0 +1,73 @@
+package decl
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kxnes/mixer"
+)
+
+func TestDirective(t *testing.T) {
+	cases := []struct {
+		name string
+		line string
+		want mixer.RouteDecl
+		ok   bool
+	}{
+		{
+			name: "with name",
+			line: "//mixer:route GET /catalog/:int Retrieve(id) catalog",
+			want: mixer.RouteDecl{Method: "GET", Pattern: "/catalog/:int", Handler: "Retrieve(id)", Name: "catalog"},
+			ok:   true,
+		},
+		{
+			name: "indented without name",
+			line: "\t// mixer:route POST /catalog Create",
+			want: mixer.RouteDecl{Method: "POST", Pattern: "/catalog", Handler: "Create"},
+			ok:   true,
+		},
+		{
+			name: "not directive",
+			line: "// mixer:route GET /catalog",
+		},
+		{
+			name: "other directive",
+			line: "//mixer:routes GET /catalog Create",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := Directive(c.line)
+			if got != c.want || ok != c.ok {
+				t.Errorf("Directive() got = %+v, %t, want = %+v, %t", got, ok, c.want, c.ok)
+			}
+		})
+	}
+}
+
+func TestRegistration(t *testing.T) {
+	cases := []struct {
+		name    string
+		method  string
+		pattern int
+		ok      bool
+	}{
+		{name: "Handle", method: "", pattern: 1, ok: true},
+		{name: "TryHandle", method: "", pattern: 1, ok: true},
+		{name: "Get", method: http.MethodGet, pattern: 0, ok: true},
+		{name: "DeleteFunc", method: http.MethodDelete, pattern: 0, ok: true},
+		{name: "GET"},
+		{name: "ServeFiles"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			method, pattern, ok := Registration(c.name)
+			if method != c.method || pattern != c.pattern || ok != c.ok {
+				t.Errorf("Registration() got = %q, %d, %t, want = %q, %d, %t",
+					method, pattern, ok, c.method, c.pattern, c.ok)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package mixer
 
 import (
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -27,18 +30,18 @@ func TestPatternError(t *testing.T) {
 	as.Equal(patternError("method", "pattern"), exp, "patternError() got")
 }
 
-func TestDuplicateError(t *testing.T) {
-	exp := &ServeMuxError{"method", "pattern", ErrDuplicate}
+func TestNotFoundError(t *testing.T) {
+	exp := &ServeMuxError{"method", "pattern", ErrNotFound}
 
 	as := Assert{t}
-	as.Equal(duplicateError("method", "pattern"), exp, "duplicateError() got")
+	as.Equal(notFoundError("method", "pattern"), exp, "notFoundError() got")
 }
 
-func TestNotFoundError(t *testing.T) {
-	exp := &ServeMuxError{"method", "pattern", ErrNotFound}
+func TestParamError(t *testing.T) {
+	exp := &ServeMuxError{"", ":1", ErrPathParamType}
 
 	as := Assert{t}
-	as.Equal(notFoundError("method", "pattern"), exp, "notFoundError() got")
+	as.Equal(paramError(1, ErrPathParamType), exp, "paramError() got")
 }
 
 func TestIntConv(t *testing.T) {
@@ -410,7 +413,45 @@ func TestNodeInsert(t *testing.T) {
 	}
 }
 
-func TestServeMuxAddLogicCases(t *testing.T) {
+// segments returns the tree segments of parts with the kinds by their text.
+func segments(parts []string) []Segment {
+	segs := make([]Segment, 0, len(parts))
+
+	for _, part := range parts {
+		kind := StaticNode
+
+		switch {
+		case part == catchAllToken:
+			kind = CatchAllNode
+		case part == pathToken:
+			kind = SlashNode
+		case strings.HasPrefix(part, typeToken):
+			kind = ParamNode
+		}
+
+		segs = append(segs, Segment{Kind: kind, Value: part})
+	}
+
+	return segs
+}
+
+// insert builds parts to the tree of mux and returns the methods of the last node.
+func insert(mux *ServeMux, parts []string) (map[string]http.Handler, error) {
+	cp, last, err := mux.build(mux.tree, segments(parts))
+	if err != nil {
+		return nil, err
+	}
+
+	if last.Methods == nil {
+		last.Methods = make(map[string]http.Handler)
+	}
+
+	*mux.tree = *cp
+
+	return last.Methods, nil
+}
+
+func TestServeMuxBuildLogicCases(t *testing.T) {
 	mux := New() // for direct compatibility (for not to remap the converters)
 	exp := &tree{root: &node{tid: root}}
 	as := Assert{t}
@@ -421,7 +462,7 @@ func TestServeMuxAddLogicCases(t *testing.T) {
 			"b": {Methods: map[string]http.Handler{}},
 		}},
 	}
-	_, err := mux.insert(parts)
+	_, err := insert(mux, parts)
 
 	as.Equal(err, nil, "without trailing slash")
 	as.EqualIndent(mux.tree, exp, "without trailing slash")
@@ -435,7 +476,7 @@ func TestServeMuxAddLogicCases(t *testing.T) {
 			tid:     slash,
 			Methods: map[string]http.Handler{},
 		}}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, nil, "with trailing slash")
 	as.EqualIndent(mux.tree, exp, "with trailing slash")
@@ -444,7 +485,7 @@ func TestServeMuxAddLogicCases(t *testing.T) {
 	exp.root.
 		Children["a"].
 		Children["d"] = &node{Methods: map[string]http.Handler{}}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, nil, "split paths")
 	as.EqualIndent(mux.tree, exp, "split paths")
@@ -454,26 +495,26 @@ func TestServeMuxAddLogicCases(t *testing.T) {
 		Children["a"].
 		Children["b"].
 		Children[":"] = &node{tid: param, conv: mux.converters["int"], Methods: map[string]http.Handler{}}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, nil, "typed path param")
 	as.EqualIndent(mux.tree, exp, "typed path param")
 
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 	as.Equal(err, nil, "duplicate typed path param")
 	as.EqualIndent(mux.tree, exp, "duplicate typed path param")
 
 	parts = []string{"/"}
 	exp.root.
 		Children["/"] = &node{tid: slash, Methods: map[string]http.Handler{}}
-	hm, err := mux.insert(parts)
+	hm, err := insert(mux, parts)
 
 	as.Equal(err, nil, "add root")
 	as.EqualIndent(mux.tree, exp, "add root")
 
 	hm[http.MethodGet] = TestHandler("/")
 	exp.root.Children["/"].Methods[http.MethodGet] = TestHandler("/")
-	hm, err = mux.insert(parts)
+	hm, err = insert(mux, parts)
 
 	as.Equal(err, nil, "correct handler map")
 	as.EqualIndent(mux.tree, exp, "correct handler map")
@@ -489,26 +530,26 @@ func TestServeMuxAddLogicCases(t *testing.T) {
 			conv:    mux.converters[""],
 			Methods: map[string]http.Handler{http.MethodPut: TestHandler("a/b/:int/:")},
 		}}
-	hm, err = mux.insert(parts)
+	hm, err = insert(mux, parts)
 	hm[http.MethodPut] = TestHandler("a/b/:int/:")
 
 	as.Equal(err, nil, "correct handler map and another converter")
 	as.EqualIndent(mux.tree, exp, "correct handler map and another converter")
 
 	parts = []string{"a", ":int"}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, ErrMultiplePathParam, "different type (b vs. :int)")
 	as.EqualIndent(mux.tree, exp, "different type (b vs. :int)")
 
 	parts = []string{"a", "b", ":str"}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, ErrMultiplePathParam, "different type (:int vs. :str)")
 	as.EqualIndent(mux.tree, exp, "different type (:int vs. :str)")
 
 	parts = []string{"a", "b", "c"}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, ErrMultiplePathParam, "different type (:int vs. c)")
 	as.EqualIndent(mux.tree, exp, "different type (:int vs. c)")
@@ -519,7 +560,7 @@ func TestServeMuxAddLogicCases(t *testing.T) {
 		Children["b"].
 		Children[":"].
 		Children["/"] = &node{tid: slash, Methods: map[string]http.Handler{}}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, nil, "different type (:int vs. /)")
 	as.EqualIndent(mux.tree, exp, "different type (:int vs. /)")
@@ -534,7 +575,7 @@ func TestServeMuxAddLogicCases(t *testing.T) {
 		"c": {
 			Methods: map[string]http.Handler{},
 		}}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, nil, "invariant conv")
 	as.EqualIndent(mux.tree, exp, "invariant conv")
@@ -546,37 +587,37 @@ func TestServeMuxAddLogicCases(t *testing.T) {
 		Children[":"].
 		Children[":"].
 		Children["/"] = &node{tid: slash, Methods: map[string]http.Handler{}}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, nil, "invariant for /")
 	as.EqualIndent(mux.tree, exp, "invariant for /")
 
 	parts = []string{"a", "b", ":int", ":", ":str"}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, ErrMultiplePathParam, "prevent /, c and : together")
 	as.EqualIndent(mux.tree, exp, "prevent /, c and : together")
 
 	parts = []string{"a", "b", ":mem"}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, ErrPathParam, "invalid path param")
 	as.EqualIndent(mux.tree, exp, "invalid path param")
 
 	parts = []string{"g", "g", "w", "p", ":gl"}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, ErrPathParam, "deep copy valid (new path)")
 	as.EqualIndent(mux.tree, exp, "deep copy valid (new path)")
 
 	parts = []string{"a", "b", ":int", ":", "a", "b", ":hf"}
-	_, err = mux.insert(parts)
+	_, err = insert(mux, parts)
 
 	as.Equal(err, ErrPathParam, "deep copy valid (exist path)")
 	as.EqualIndent(mux.tree, exp, "deep copy valid (exist path)")
 }
 
-func TestServeMuxAddDirectCases(t *testing.T) {
+func TestServeMuxBuildDirectCases(t *testing.T) {
 	mux := New() // for direct compatibility (for not to remap the converters)
 
 	cases := []struct {
@@ -816,13 +857,532 @@ func TestServeMuxAddDirectCases(t *testing.T) {
 		t.Run(c.name, func(t *testing.T) {
 			mux.tree.root = c.root
 
-			_, got := mux.insert(c.parts)
+			_, got := insert(mux, c.parts)
 
 			as := Assert{t}
-			as.Equal(got, c.want, "ServeMux.add() error")
-			as.EqualIndent(mux.tree.root, c.wantRoot, "ServeMux.add() tree")
+			as.Equal(got, c.want, "ServeMux.build() error")
+			as.EqualIndent(mux.tree.root, c.wantRoot, "ServeMux.build() tree")
 
 			mux.tree.root = nil
 		})
 	}
 }
+
+func TestSplitPattern(t *testing.T) {
+	cases := []struct {
+		name    string
+		pattern string
+		host    string
+		path    string
+	}{
+		{
+			name:    "path only",
+			pattern: "/a/b/",
+			host:    "",
+			path:    "/a/b/",
+		},
+		{
+			name:    "host and path",
+			pattern: ":str.example.com/a/b",
+			host:    ":str.example.com",
+			path:    "/a/b",
+		},
+		{
+			name:    "host only",
+			pattern: "example.com",
+			host:    "example.com",
+			path:    "",
+		},
+		{
+			name:    "relative path",
+			pattern: "catalog/",
+			host:    "",
+			path:    "catalog/",
+		},
+		{
+			name:    "param host",
+			pattern: ":str/a",
+			host:    ":str",
+			path:    "/a",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			host, path := splitPattern(c.pattern)
+
+			as := Assert{t}
+			as.StrEqual(host, c.host, "splitPattern() host")
+			as.StrEqual(path, c.path, "splitPattern() path")
+		})
+	}
+}
+
+func TestSplitHost(t *testing.T) {
+	cases := []struct {
+		name string
+		host string
+		want []string
+		err  error
+	}{
+		{
+			name: "empty host",
+			host: "",
+			want: nil,
+			err:  nil,
+		},
+		{
+			name: "static labels",
+			host: "API.Example.com",
+			want: []string{"com", "example", "api"},
+			err:  nil,
+		},
+		{
+			name: "typed labels",
+			host: ":str.api.:int.com",
+			want: []string{"com", ":int", "api", ":str"},
+			err:  nil,
+		},
+		{
+			name: "empty label",
+			host: "api..com",
+			want: []string{"com", "", "api"},
+			err:  ErrPattern,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := splitHost(c.host)
+
+			as := Assert{t}
+			as.Equal(got, c.want, "splitHost() got")
+			as.Equal(err, c.err, "splitHost() error")
+		})
+	}
+}
+
+func TestStripPort(t *testing.T) {
+	cases := []struct {
+		host string
+		want string
+	}{
+		{host: "example.com", want: "example.com"},
+		{host: "example.com:8080", want: "example.com"},
+		{host: "[::1]:8080", want: "::1"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.host, func(t *testing.T) {
+			as := Assert{t}
+			as.StrEqual(stripPort(c.host), c.want, "stripPort() got")
+		})
+	}
+}
+
+func TestNodeSearch(t *testing.T) {
+	mux := New() // for direct compatibility (for not to remap the converters)
+	leaf := &node{tid: slash}
+	n := &node{Children: map[string]*node{
+		"a": {Children: map[string]*node{
+			":": {tid: param, conv: mux.converters["int"], Children: map[string]*node{
+				"/": leaf,
+			}},
+		}},
+	}}
+
+	params := PathParams{0: "host"}
+	got := n.search([]string{"a", "12", "/"}, params, nil)
+
+	as := Assert{t}
+	as.PtrEqual(got, leaf, "found node")
+	as.Equal(params, PathParams{0: "host", 1: 12}, "found params")
+
+	got = n.search([]string{"a", "b", "/"}, make(PathParams), nil)
+	as.Equal(got, (*node)(nil), "invalid param")
+
+	got = n.search([]string{"b"}, make(PathParams), nil)
+	as.Equal(got, (*node)(nil), "not exist")
+
+	got = n.search([]string{"a", ":", "/"}, make(PathParams), nil)
+	as.Equal(got, (*node)(nil), "type token is not static")
+
+	params = make(PathParams)
+	got = n.search([]string{"%61", "%31%32", "/"}, params, mux.segment)
+
+	as.PtrEqual(got, leaf, "unescaped node")
+	as.Equal(params, PathParams{0: 12}, "unescaped params")
+
+	got = n.search([]string{"a", "12", "%2F"}, make(PathParams), mux.segment)
+	as.Equal(got, (*node)(nil), "escaped slash is not trailing slash")
+
+	got = n.search([]string{"a", "%zz"}, make(PathParams), mux.segment)
+	as.Equal(got, (*node)(nil), "invalid escape")
+
+	got = n.search([]string{"A", "12", "/"}, make(PathParams), New(WithCaseInsensitive(false)).segment)
+	as.PtrEqual(got, leaf, "folded key")
+}
+
+func TestServeMuxSegment(t *testing.T) {
+	upper := func(s string) string { return strings.ToUpper(s) }
+
+	cases := []struct {
+		name  string
+		mux   *ServeMux
+		part  string
+		value string
+		key   string
+		err   error
+	}{
+		{
+			name:  "default",
+			mux:   New(),
+			part:  "Hello%20World",
+			value: "Hello World",
+			key:   "Hello World",
+		},
+		{
+			name:  "raw",
+			mux:   New(WithRawPathParams()),
+			part:  "Hello%20World",
+			value: "Hello%20World",
+			key:   "Hello%20World",
+		},
+		{
+			name:  "case-insensitive",
+			mux:   New(WithCaseInsensitive(false)),
+			part:  "Hello%20World",
+			value: "Hello World",
+			key:   "hello world",
+		},
+		{
+			name:  "normalizer",
+			mux:   New(WithNormalizer(upper)),
+			part:  "Hello%20World",
+			value: "HELLO WORLD",
+			key:   "HELLO WORLD",
+		},
+		{
+			name: "invalid escape",
+			mux:  New(),
+			part: "%zz",
+			err:  url.EscapeError("%zz"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			value, key, err := c.mux.segment(c.part)
+
+			as := Assert{t}
+			as.StrEqual(value, c.value, "ServeMux.segment() value")
+			as.StrEqual(key, c.key, "ServeMux.segment() key")
+			as.Equal(err, c.err, "ServeMux.segment() error")
+		})
+	}
+}
+
+func TestServeMuxKeys(t *testing.T) {
+	mux := New(WithCaseInsensitive(false), WithNormalizer(strings.TrimSpace))
+	segs := segments([]string{" Catalog ", ":int", "Items", ":", "/"})
+	segs = append(segs, Segment{Kind: StaticNode, Value: "%3Aint"})
+
+	mux.keys(segs)
+
+	want := segments([]string{"catalog", ":int", "items", ":", "/"})
+	want = append(want, Segment{Kind: StaticNode, Value: ":int"})
+
+	as := Assert{t}
+	as.Equal(segs, want, "ServeMux.keys() got")
+}
+
+func TestServeMuxCanonical(t *testing.T) {
+	mux := New(WithCaseInsensitive(true))
+
+	cases := []struct {
+		name    string
+		pattern string
+		parts   []string
+		want    string
+		changed bool
+	}{
+		{
+			name:    "canonical",
+			pattern: "/Catalog/:int/",
+			parts:   []string{"Catalog", "12", "/"},
+			want:    "/Catalog/12/",
+			changed: false,
+		},
+		{
+			name:    "another case",
+			pattern: "example.com/Catalog/:str",
+			parts:   []string{"cAtAlOg", "AbC%20D"},
+			want:    "/Catalog/AbC%20D",
+			changed: true,
+		},
+		{
+			name:    "escaped static part",
+			pattern: "/Café",
+			parts:   []string{"CAF%C3%A9"},
+			want:    "/Caf%C3%A9",
+			changed: true,
+		},
+		{
+			name:    "catch-all",
+			pattern: "/Files/*",
+			parts:   []string{"files", "Read%20Me", "/"},
+			want:    "/Files/Read%20Me/",
+			changed: true,
+		},
+		{
+			name:    "canonical catch-all",
+			pattern: "/Files/*",
+			parts:   []string{"Files", "readme"},
+			want:    "/Files/readme",
+			changed: false,
+		},
+		{
+			name:    "catch-all without rest",
+			pattern: "/Files/*",
+			parts:   []string{"files"},
+			want:    "",
+			changed: false,
+		},
+		{
+			name:    "different length",
+			pattern: "/Catalog/",
+			parts:   []string{"catalog"},
+			want:    "",
+			changed: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, changed := mux.canonical(c.pattern, c.parts)
+
+			as := Assert{t}
+			as.StrEqual(got, c.want, "ServeMux.canonical() got")
+			as.BoolEqual(changed, c.changed, "ServeMux.canonical() changed")
+		})
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	cases := []struct {
+		name     string
+		method   string
+		url      string
+		code     int
+		location string
+	}{
+		{
+			name:     "get",
+			method:   http.MethodGet,
+			url:      "/Catalog/?page=2",
+			code:     http.StatusMovedPermanently,
+			location: "/catalog/?page=2",
+		},
+		{
+			name:     "post",
+			method:   http.MethodPost,
+			url:      "/Catalog/",
+			code:     http.StatusPermanentRedirect,
+			location: "/catalog/",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := mustReq(http.NewRequest(c.method, c.url, nil))
+			w := httptest.NewRecorder()
+
+			redirectHandler(req, "/catalog/").ServeHTTP(w, req)
+
+			as := Assert{t}
+			as.IntEqual(w.Code, c.code, "redirectHandler() code")
+			as.StrEqual(w.Header().Get("Location"), c.location, "redirectHandler() location")
+		})
+	}
+}
+
+func TestAddCandidate(t *testing.T) {
+	plain := &route{handler: TestHandler("plain")}
+	cond := &route{handler: TestHandler("cond"), matchers: []Matcher{SchemeMatcher("https")}}
+
+	as := Assert{t}
+
+	got, err := addCandidate(nil, plain)
+	as.Equal(got, candidates{plain}, "empty got")
+	as.Equal(err, nil, "empty error")
+
+	got, err = addCandidate(TestHandler("handler"), plain)
+	as.Equal(got, candidates(nil), "not candidates got")
+	as.Equal(err, ErrDuplicate, "not candidates error")
+
+	origin := candidates{cond}
+	got, err = addCandidate(origin, plain)
+
+	as.Equal(got, candidates{cond, plain}, "conditional before got")
+	as.Equal(err, nil, "conditional before error")
+	as.IntEqual(len(origin), 1, "conditional before origin")
+
+	got, err = addCandidate(candidates{plain}, cond)
+	as.Equal(got, candidates(nil), "unreachable got")
+	as.Equal(err, ErrDuplicate, "unreachable error")
+}
+
+func TestCandidatesMatch(t *testing.T) {
+	fail := func(err error) Matcher {
+		return func(*http.Request) error { return err }
+	}
+	found := &route{handler: TestHandler("found")}
+	notFound := &route{matchers: []Matcher{fail(ErrNotFound)}}
+	notAcceptable := &route{matchers: []Matcher{fail(ErrNotAcceptable)}}
+	unsupported := &route{matchers: []Matcher{fail(nil), fail(ErrUnsupportedMediaType)}}
+
+	cases := []struct {
+		name string
+		cs   candidates
+		want *route
+		err  error
+	}{
+		{
+			name: "first matched",
+			cs:   candidates{notFound, found},
+			want: found,
+			err:  nil,
+		},
+		{
+			name: "not found",
+			cs:   candidates{notFound},
+			want: nil,
+			err:  ErrNotFound,
+		},
+		{
+			name: "not acceptable",
+			cs:   candidates{notFound, notAcceptable, notFound},
+			want: nil,
+			err:  ErrNotAcceptable,
+		},
+		{
+			name: "unsupported media type",
+			cs:   candidates{unsupported, notAcceptable},
+			want: nil,
+			err:  ErrUnsupportedMediaType,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.cs.match(nil)
+
+			as := Assert{t}
+			as.Equal(got, c.want, "candidates.match() got")
+			as.Equal(err, c.err, "candidates.match() error")
+		})
+	}
+}
+
+func TestServeError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{err: notFoundError("GET", "/"), want: http.StatusNotFound},
+		{err: &ServeMuxError{"GET", "/", ErrNotAcceptable}, want: http.StatusNotAcceptable},
+		{err: &ServeMuxError{"GET", "/", ErrUnsupportedMediaType}, want: http.StatusUnsupportedMediaType},
+		{err: &PanicError{Value: "boom"}, want: http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		t.Run(c.err.Error(), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			serveError(w, mustReq(http.NewRequest(http.MethodGet, "/", nil)), c.err)
+
+			as := Assert{t}
+			as.IntEqual(w.Code, c.want, "serveError() code")
+		})
+	}
+}
+
+func TestNodeMethods(t *testing.T) {
+	n := &node{Methods: map[string]http.Handler{
+		http.MethodPut:    TestHandler("put"),
+		http.MethodGet:    TestHandler("get"),
+		http.MethodDelete: nil,
+	}}
+
+	as := Assert{t}
+	as.Equal(n.methods(), []string{http.MethodGet, http.MethodPut}, "node.methods() got")
+	as.Equal((&node{}).methods(), []string{}, "node.methods() empty")
+}
+
+func TestServeMuxHandleCopy(t *testing.T) {
+	mux := New()
+	mux.Get("/a/:int", TestHandler("a"))
+	mux.Get("host.com/b/", TestHandler("b"))
+
+	paths, hosts := mux.tree.String(), mux.hosts.String()
+
+	for _, pattern := range []string{"/a/:int", "host.com/b/", "/c/"} {
+		_, _, err := mux.handle(mux.tree, mux.hosts, http.MethodPut, pattern, TestHandler("put"), nil)
+		must(err)
+	}
+
+	as := Assert{t}
+	as.StrEqual(mux.tree.String(), paths, "ServeMux.handle() tree")
+	as.StrEqual(mux.hosts.String(), hosts, "ServeMux.handle() hosts")
+	as.StrEqual(mux.hosts.root.Children["com"].Children["host"].sub.String(),
+		`{
+	"methods": null,
+	"children": {
+		"b": {
+			"methods": null,
+			"children": {
+				"/": {
+					"methods": {
+						"GET": [
+							{}
+						]
+					},
+					"children": null
+				}
+			}
+		}
+	}
+}`, "ServeMux.handle() host tree")
+}
+
+func TestNodeWalk(t *testing.T) {
+	n := &node{Children: map[string]*node{
+		"a": {Children: map[string]*node{"b": {}}},
+		"c": {},
+	}}
+	count := 0
+
+	n.walk(func(*node) { count++ })
+
+	as := Assert{t}
+	as.IntEqual(count, 4, "node.walk() count")
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+
+	mw := func(s string) Middleware {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, s)
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+	h := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { calls = append(calls, "handler") })
+
+	chain(h, []Middleware{mw("first"), mw("second")}).ServeHTTP(nil, nil)
+
+	as := Assert{t}
+	as.Equal(calls, []string{"first", "second", "handler"}, "chain() calls")
+	as.PtrEqual(chain(h, nil), h, "chain() without middleware")
+}
@@ -0,0 +1,345 @@
+package mixer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrRouteFile signals that the route file is malformed.
+	ErrRouteFile = errors.New("invalid route file")
+
+	// ErrUnknownHandler signals that the handler of the route file is not in the registry.
+	ErrUnknownHandler = errors.New("unknown handler")
+)
+
+type (
+	// Handlers represents the registry of the handlers by name for the route files.
+	Handlers map[string]http.Handler
+
+	// LineError decorates the error of the route file with the line number.
+	LineError struct {
+		Line int
+		Err  error
+	}
+
+	// RouteDecl represents the route declaration of the route file.
+	RouteDecl struct {
+		Method  string `json:"method"`
+		Pattern string `json:"pattern"`
+		Handler string `json:"handler"`
+		Name    string `json:"name"`
+
+		// Line is the line of the declaration in the route file.
+		Line int `json:"-"`
+	}
+)
+
+// Error implements the error's Error.
+func (e *LineError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+// Unwrap implements the error's Unwrap.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LoadFile loads the route file by LoadJSON or LoadYAML depending on the extension.
+func (mux *ServeMux) LoadFile(path string, handlers Handlers) error {
+	decls, errs, err := readRouteFile(path)
+	if err != nil {
+		return err
+	}
+
+	return mux.load(decls, errs, handlers)
+}
+
+// ReadRouteFile returns the route declarations of the JSON or YAML route file
+// depending on the extension. The syntax errors are returned as ServeMuxErrors.
+// It is useful for the tools working with the route files, e.g. cmd/mixer-gen.
+func ReadRouteFile(path string) ([]RouteDecl, error) {
+	decls, errs, err := readRouteFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(errs) != 0 {
+		return decls, errs
+	}
+
+	return decls, nil
+}
+
+// readRouteFile decodes the route file depending on the extension.
+// Returns the error if the file can not be opened.
+func readRouteFile(path string) ([]RouteDecl, ServeMuxErrors, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var (
+		decls []RouteDecl
+		errs  ServeMuxErrors
+	)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decls, errs = decodeYAML(f)
+	default:
+		decls, errs = decodeJSON(f)
+	}
+
+	return decls, errs, nil
+}
+
+// LoadJSON registers the routes of the JSON array, e.g.
+//
+//	[{"method": "GET", "pattern": "/a/:int", "handler": "getA", "name": "a"}]
+//
+// The handlers are looked up in the registry by name. The routes are validated
+// like by Register and all problems are returned as ServeMuxErrors with LineError.
+func (mux *ServeMux) LoadJSON(r io.Reader, handlers Handlers) error {
+	entries, errs := decodeJSON(r)
+	return mux.load(entries, errs, handlers)
+}
+
+// LoadYAML registers the routes of the YAML sequence of flat mappings, e.g.
+//
+//   - method: GET
+//     pattern: /a/:int
+//     handler: getA
+//
+// Only this subset of YAML is supported: comments, plain and quoted scalars.
+// The routes are handled like by LoadJSON.
+func (mux *ServeMux) LoadYAML(r io.Reader, handlers Handlers) error {
+	entries, errs := decodeYAML(r)
+	return mux.load(entries, errs, handlers)
+}
+
+// load validates entries and registers them if there are no errors.
+func (mux *ServeMux) load(entries []RouteDecl, errs ServeMuxErrors, handlers Handlers) error {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+
+	paths, hosts := mux.tree, mux.hosts
+
+	for _, e := range entries {
+		h, ok := handlers[e.Handler]
+		if !ok {
+			errs = append(errs, &ServeMuxError{e.Method, e.Pattern, &LineError{e.Line, ErrUnknownHandler}})
+			h = http.NotFoundHandler() // still validate the pattern
+		}
+
+		var opts []RouteOption
+		if e.Name != "" {
+			opts = append(opts, WithName(e.Name))
+		}
+
+		p, hs, err := mux.handle(paths, hosts, e.Method, e.Pattern, h, opts)
+		if err != nil {
+			se := err.(*ServeMuxError)
+			errs = append(errs, &ServeMuxError{se.method, se.pattern, &LineError{e.Line, se.err}})
+
+			continue
+		}
+
+		paths, hosts = p, hs
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	mux.tree, mux.hosts = paths, hosts
+
+	return nil
+}
+
+// decodeJSON decodes the route entries with their lines.
+// The syntax error stops decoding, other errors are reported per entry.
+func decodeJSON(r io.Reader) ([]RouteDecl, ServeMuxErrors) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, ServeMuxErrors{{"", "", err}}
+	}
+
+	br := bytes.NewReader(data)
+	dec := json.NewDecoder(br)
+	dec.DisallowUnknownFields()
+
+	// line returns the line of the next value in data.
+	// Buffered returns a new reader on every call, so it is drained to count the length.
+	line := func() int {
+		buffered, _ := io.Copy(ioutil.Discard, dec.Buffered())
+		off := len(data) - br.Len() - int(buffered)
+		for off < len(data) && strings.IndexByte(" \t\r\n,", data[off]) >= 0 {
+			off++
+		}
+
+		return 1 + bytes.Count(data[:off], []byte("\n"))
+	}
+
+	fail := func(err error) ServeMuxErrors {
+		if se, ok := err.(*json.SyntaxError); ok {
+			l := 1 + bytes.Count(data[:se.Offset], []byte("\n"))
+			err = &LineError{l, fmt.Errorf("%w: %s", ErrRouteFile, se)}
+		}
+
+		return ServeMuxErrors{{"", "", err}}
+	}
+
+	if tok, err := dec.Token(); err != nil {
+		return nil, fail(err)
+	} else if tok != json.Delim('[') {
+		return nil, ServeMuxErrors{{"", "", &LineError{1, fmt.Errorf("%w: expected array", ErrRouteFile)}}}
+	}
+
+	var (
+		entries []RouteDecl
+		errs    ServeMuxErrors
+	)
+
+	for dec.More() {
+		l := line()
+
+		var e RouteDecl
+		if err := dec.Decode(&e); err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				return entries, append(errs, fail(err)...)
+			}
+
+			errs = append(errs, &ServeMuxError{"", "", &LineError{l, fmt.Errorf("%w: %s", ErrRouteFile, err)}})
+
+			continue
+		}
+
+		e.Line = l
+		entries = append(entries, e)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return entries, append(errs, fail(err)...)
+	}
+
+	return entries, errs
+}
+
+// decodeYAML decodes the route entries of the YAML subset with their lines.
+func decodeYAML(r io.Reader) ([]RouteDecl, ServeMuxErrors) {
+	var (
+		entries []RouteDecl
+		errs    ServeMuxErrors
+	)
+
+	syntax := func(l int, msg string) {
+		errs = append(errs, &ServeMuxError{"", "", &LineError{l, fmt.Errorf("%w: %s", ErrRouteFile, msg)}})
+	}
+
+	sc := bufio.NewScanner(r)
+
+	for l := 1; sc.Scan(); l++ {
+		text := stripComment(sc.Text())
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		trimmed := strings.TrimLeft(text, " ")
+
+		switch {
+		case strings.HasPrefix(trimmed, "- ") || trimmed == "-":
+			entries = append(entries, RouteDecl{Line: l})
+			trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+
+			if trimmed == "" {
+				continue
+			}
+		case len(entries) == 0 || len(trimmed) == len(text):
+			syntax(l, "expected sequence item")
+			continue
+		}
+
+		i := strings.IndexByte(trimmed, ':')
+		if i < 0 {
+			syntax(l, "expected key: value")
+			continue
+		}
+
+		key, value := strings.TrimSpace(trimmed[:i]), strings.TrimSpace(trimmed[i+1:])
+
+		value, err := unquote(value)
+		if err != nil {
+			syntax(l, "invalid quoted value")
+			continue
+		}
+
+		e := &entries[len(entries)-1]
+
+		switch key {
+		case "method":
+			e.Method = value
+		case "pattern":
+			e.Pattern = value
+		case "handler":
+			e.Handler = value
+		case "name":
+			e.Name = value
+		default:
+			syntax(l, "unknown key "+strconv.Quote(key))
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		errs = append(errs, &ServeMuxError{"", "", err})
+	}
+
+	return entries, errs
+}
+
+// stripComment removes the YAML comment from the line, `#` within quotes is kept.
+func stripComment(s string) string {
+	var quote byte
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+
+	return s
+}
+
+// unquote returns the value of the plain or quoted YAML scalar.
+func unquote(s string) (string, error) {
+	switch {
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		return strconv.Unquote(s)
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	case strings.HasPrefix(s, `"`) || strings.HasPrefix(s, `'`):
+		return "", ErrRouteFile
+	default:
+		return s, nil
+	}
+}
@@ -0,0 +1,152 @@
+package mixer
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServeMuxLoadJSON(t *testing.T) {
+	handlers := Handlers{"getA": TestHandler("a"), "postA": TestHandler("post")}
+
+	cases := []struct {
+		name string
+		file string
+		want error
+	}{
+		{
+			name: "valid",
+			file: `[
+	{"method": "GET", "pattern": "/a/:int", "handler": "getA", "name": "a"},
+	{"method": "POST", "pattern": "/a/:int", "handler": "postA"}
+]`,
+			want: nil,
+		},
+		{
+			name: "all problems",
+			file: `[
+	{"method": "GET", "pattern": "/a/:int", "handler": "getA"},
+	{"method": "GET", "pattern": "/a/:int", "handler": "getA"},
+	{"method": "PUT", "pattern": "/a/:str", "handler": "putA"},
+	{"method": "GET", "pattern": "/b", "handler": "getA", "extra": 1}
+]`,
+			want: ServeMuxErrors{
+				{"", "", &LineError{5, errors.New(`invalid route file: json: unknown field "extra"`)}},
+				{http.MethodGet, "/a/:int", &LineError{3, &Conflict{
+					Method: http.MethodGet, Pattern: "/a/:int", Segment: -1, err: ErrDuplicate,
+				}}},
+				{http.MethodPut, "/a/:str", &LineError{4, ErrUnknownHandler}},
+				{http.MethodPut, "/a/:str", &LineError{4, &Conflict{
+					Method: http.MethodGet, Pattern: "/a/:int", Segment: 1, Kind: ParamNode, ExistingKind: ParamNode,
+					err: ErrMultiplePathParam,
+				}}},
+			},
+		},
+		{
+			name: "syntax",
+			file: "[\n\t{\"method\": \"GET\",,}\n]",
+			want: ServeMuxErrors{
+				{"", "", &LineError{2, errors.New("invalid route file: invalid character ',' looking for beginning of object key string")}},
+			},
+		},
+		{
+			name: "not array",
+			file: `{}`,
+			want: ServeMuxErrors{{"", "", &LineError{1, errors.New("invalid route file: expected array")}}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mux := New()
+			err := mux.LoadJSON(strings.NewReader(c.file), handlers)
+
+			as := Assert{t}
+			if c.want == nil {
+				as.Equal(err, nil, "ServeMux.LoadJSON() error")
+				as.IntEqual(len(mux.Routes()), 2, "ServeMux.LoadJSON() routes")
+
+				return
+			}
+
+			as.StrEqual(err.Error(), c.want.Error(), "ServeMux.LoadJSON() error")
+			as.IntEqual(len(mux.Routes()), 0, "ServeMux.LoadJSON() untouched")
+		})
+	}
+}
+
+func TestServeMuxLoadYAML(t *testing.T) {
+	file := `# routes
+- method: GET
+  pattern: "/a/:int" # comment
+  handler: getA
+  name: 'a''s'
+-
+  method: POST
+  pattern: /a/:int
+  handler: postA
+`
+
+	mux := New()
+	err := mux.LoadYAML(strings.NewReader(file), Handlers{"getA": TestHandler("a"), "postA": TestHandler("post")})
+
+	as := Assert{t}
+	as.Equal(err, nil, "ServeMux.LoadYAML() error")
+	as.Equal(mux.Routes(), []Route{
+		{Method: http.MethodGet, Pattern: "/a/:int", Name: "a's", Handler: TestHandler("a")},
+		{Method: http.MethodPost, Pattern: "/a/:int", Handler: TestHandler("post")},
+	}, "ServeMux.LoadYAML() routes")
+
+	file = `method: GET
+- method: GET
+  path: /b
+  handler "x"
+  pattern: "/b
+`
+	err = New().LoadYAML(strings.NewReader(file), nil)
+	want := ServeMuxErrors{
+		{"", "", &LineError{1, errors.New("invalid route file: expected sequence item")}},
+		{"", "", &LineError{3, errors.New(`invalid route file: unknown key "path"`)}},
+		{"", "", &LineError{4, errors.New("invalid route file: expected key: value")}},
+		{"", "", &LineError{5, errors.New("invalid route file: invalid quoted value")}},
+		{http.MethodGet, "", &LineError{2, ErrUnknownHandler}},
+		{http.MethodGet, "", &LineError{2, &PatternError{0, "missing path", "the path must start with '/'", ErrPattern}}},
+	}
+
+	as.StrEqual(err.Error(), want.Error(), "ServeMux.LoadYAML() error")
+}
+
+func TestServeMuxLoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mixer")
+	must(err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "routes.yml")
+	must(ioutil.WriteFile(path, []byte("- method: GET\n  pattern: /a\n  handler: a\n"), 0o600))
+
+	mux := New()
+
+	as := Assert{t}
+	as.Equal(mux.LoadFile(path, Handlers{"a": TestHandler("a")}), nil, "ServeMux.LoadFile() error")
+	as.IntEqual(len(mux.Routes()), 1, "ServeMux.LoadFile() routes")
+
+	decls, err := ReadRouteFile(path)
+	as.Equal(err, nil, "ReadRouteFile() error")
+	as.Equal(decls, []RouteDecl{{Method: "GET", Pattern: "/a", Handler: "a", Line: 1}}, "ReadRouteFile() got")
+
+	pe, ok := mux.LoadFile(filepath.Join(dir, "none.json"), nil).(*os.PathError)
+	as.BoolEqual(ok && pe != nil, true, "ServeMux.LoadFile() not exist")
+}
+
+func TestLineError(t *testing.T) {
+	err := &LineError{3, ErrDuplicate}
+
+	as := Assert{t}
+	as.StrEqual(err.Error(), "line 3: duplicate handler", "LineError.Error() got")
+	as.BoolEqual(errors.Is(&ServeMuxError{"GET", "/", err}, ErrDuplicate), true, "errors.Is() cause")
+}
@@ -0,0 +1,151 @@
+package mixer
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Matcher checks that the request satisfies the route condition.
+// Returns nil on success or the reason of failure: ErrNotFound,
+// ErrNotAcceptable or ErrUnsupportedMediaType.
+type Matcher func(r *http.Request) error
+
+var (
+	// ErrNotAcceptable signals that route can not produce the response acceptable by client.
+	ErrNotAcceptable = errors.New("not acceptable")
+
+	// ErrUnsupportedMediaType signals that route does not support the request content type.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// WithMatchers adds the matchers to the route.
+// The routes with matchers registered for the same method and pattern
+// are checked in the registration order and the first matched is used.
+func WithMatchers(matchers ...Matcher) RouteOption {
+	return func(rt *route) {
+		rt.matchers = append(rt.matchers, matchers...)
+	}
+}
+
+// HeaderMatcher matches the request with header key equal to value.
+// If value is empty only presence of the header is checked.
+func HeaderMatcher(key, value string) Matcher {
+	key = http.CanonicalHeaderKey(key)
+
+	return func(r *http.Request) error {
+		if contains(r.Header[key], value) {
+			return nil
+		}
+
+		return ErrNotFound
+	}
+}
+
+// QueryMatcher matches the request with query key equal to value.
+// If value is empty only presence of the key is checked.
+func QueryMatcher(key, value string) Matcher {
+	return func(r *http.Request) error {
+		if contains(r.URL.Query()[key], value) {
+			return nil
+		}
+
+		return ErrNotFound
+	}
+}
+
+// SchemeMatcher matches the request with the given scheme, e.g. "https".
+func SchemeMatcher(scheme string) Matcher {
+	scheme = strings.ToLower(scheme)
+
+	return func(r *http.Request) error {
+		got := strings.ToLower(r.URL.Scheme)
+		if got == "" {
+			got = "http"
+
+			if r.TLS != nil {
+				got = "https"
+			}
+		}
+
+		if got == scheme {
+			return nil
+		}
+
+		return ErrNotFound
+	}
+}
+
+// AcceptMatcher matches the request which accepts the given media type.
+// The request without Accept header accepts any media type.
+func AcceptMatcher(mediaType string) Matcher {
+	mediaType = strings.ToLower(mediaType)
+
+	return func(r *http.Request) error {
+		accept := r.Header.Get("Accept")
+		if accept == "" {
+			return nil
+		}
+
+		for _, rng := range strings.Split(accept, ",") {
+			mt, params, err := mime.ParseMediaType(rng)
+			if err != nil {
+				continue
+			}
+
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				continue
+			}
+
+			if mediaRange(mt, mediaType) {
+				return nil
+			}
+		}
+
+		return ErrNotAcceptable
+	}
+}
+
+// ContentTypeMatcher matches the request with the given content type.
+func ContentTypeMatcher(mediaType string) Matcher {
+	mediaType = strings.ToLower(mediaType)
+
+	return func(r *http.Request) error {
+		mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err == nil && mt == mediaType {
+			return nil
+		}
+
+		return ErrUnsupportedMediaType
+	}
+}
+
+// contains reports whether values contains value or has any if value is empty.
+func contains(values []string, value string) bool {
+	if value == "" {
+		return len(values) != 0
+	}
+
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mediaRange reports whether media range rng (e.g. "text/*") includes mediaType.
+func mediaRange(rng, mediaType string) bool {
+	if rng == "*/*" || rng == mediaType {
+		return true
+	}
+
+	if strings.HasSuffix(rng, "/*") {
+		return strings.HasPrefix(mediaType, rng[:len(rng)-1])
+	}
+
+	return false
+}
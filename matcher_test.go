@@ -0,0 +1,227 @@
+package mixer
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+)
+
+func TestWithMatchers(t *testing.T) {
+	rt := &route{}
+	WithMatchers(HeaderMatcher("a", ""), QueryMatcher("b", ""))(rt)
+	WithMatchers(SchemeMatcher("https"))(rt)
+
+	as := Assert{t}
+	as.IntEqual(len(rt.matchers), 3, "WithMatchers() got")
+}
+
+func TestHeaderMatcher(t *testing.T) {
+	cases := []struct {
+		name   string
+		key    string
+		value  string
+		header http.Header
+		want   error
+	}{
+		{
+			name:   "value",
+			key:    "x-api-version",
+			value:  "2",
+			header: http.Header{"X-Api-Version": {"1", "2"}},
+			want:   nil,
+		},
+		{
+			name:   "wrong value",
+			key:    "X-API-Version",
+			value:  "2",
+			header: http.Header{"X-Api-Version": {"1"}},
+			want:   ErrNotFound,
+		},
+		{
+			name:   "presence",
+			key:    "X-API-Version",
+			value:  "",
+			header: http.Header{"X-Api-Version": {"1"}},
+			want:   nil,
+		},
+		{
+			name:   "absence",
+			key:    "X-API-Version",
+			value:  "",
+			header: http.Header{},
+			want:   ErrNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := mustReq(http.NewRequest(http.MethodGet, "/", nil))
+			req.Header = c.header
+
+			as := Assert{t}
+			as.Equal(HeaderMatcher(c.key, c.value)(req), c.want, "HeaderMatcher() got")
+		})
+	}
+}
+
+func TestQueryMatcher(t *testing.T) {
+	cases := []struct {
+		name  string
+		key   string
+		value string
+		url   string
+		want  error
+	}{
+		{
+			name:  "value",
+			key:   "v",
+			value: "2",
+			url:   "/?v=1&v=2",
+			want:  nil,
+		},
+		{
+			name:  "wrong value",
+			key:   "v",
+			value: "2",
+			url:   "/?v=1",
+			want:  ErrNotFound,
+		},
+		{
+			name:  "presence",
+			key:   "v",
+			value: "",
+			url:   "/?v=1",
+			want:  nil,
+		},
+		{
+			name:  "absence",
+			key:   "v",
+			value: "",
+			url:   "/?w=1",
+			want:  ErrNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := mustReq(http.NewRequest(http.MethodGet, c.url, nil))
+
+			as := Assert{t}
+			as.Equal(QueryMatcher(c.key, c.value)(req), c.want, "QueryMatcher() got")
+		})
+	}
+}
+
+func TestSchemeMatcher(t *testing.T) {
+	req := mustReq(http.NewRequest(http.MethodGet, "/", nil))
+
+	as := Assert{t}
+	as.Equal(SchemeMatcher("HTTP")(req), nil, "plain request http")
+	as.Equal(SchemeMatcher("https")(req), ErrNotFound, "plain request https")
+
+	req.TLS = &tls.ConnectionState{}
+
+	as.Equal(SchemeMatcher("https")(req), nil, "tls request https")
+	as.Equal(SchemeMatcher("http")(req), ErrNotFound, "tls request http")
+
+	req = mustReq(http.NewRequest(http.MethodGet, "https://example.com/", nil))
+
+	as.Equal(SchemeMatcher("https")(req), nil, "absolute url https")
+}
+
+func TestAcceptMatcher(t *testing.T) {
+	cases := []struct {
+		name   string
+		accept string
+		want   error
+	}{
+		{
+			name:   "without header",
+			accept: "",
+			want:   nil,
+		},
+		{
+			name:   "exact",
+			accept: "text/html, application/json",
+			want:   nil,
+		},
+		{
+			name:   "subtype range",
+			accept: "application/*;q=0.5",
+			want:   nil,
+		},
+		{
+			name:   "any range",
+			accept: "text/html, */*;q=0.1",
+			want:   nil,
+		},
+		{
+			name:   "zero quality",
+			accept: "application/json;q=0",
+			want:   ErrNotAcceptable,
+		},
+		{
+			name:   "another type",
+			accept: "text/html",
+			want:   ErrNotAcceptable,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := mustReq(http.NewRequest(http.MethodGet, "/", nil))
+			req.Header.Set("Accept", c.accept)
+
+			as := Assert{t}
+			as.Equal(AcceptMatcher("application/JSON")(req), c.want, "AcceptMatcher() got")
+		})
+	}
+}
+
+func TestContentTypeMatcher(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		want        error
+	}{
+		{
+			name:        "exact",
+			contentType: "application/json",
+			want:        nil,
+		},
+		{
+			name:        "with params",
+			contentType: "Application/Json; charset=utf-8",
+			want:        nil,
+		},
+		{
+			name:        "another type",
+			contentType: "text/plain",
+			want:        ErrUnsupportedMediaType,
+		},
+		{
+			name:        "without header",
+			contentType: "",
+			want:        ErrUnsupportedMediaType,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := mustReq(http.NewRequest(http.MethodPost, "/", nil))
+			req.Header.Set("Content-Type", c.contentType)
+
+			as := Assert{t}
+			as.Equal(ContentTypeMatcher("application/json")(req), c.want, "ContentTypeMatcher() got")
+		})
+	}
+}
+
+func TestMediaRange(t *testing.T) {
+	as := Assert{t}
+	as.BoolEqual(mediaRange("*/*", "text/html"), true, "any")
+	as.BoolEqual(mediaRange("text/*", "text/html"), true, "subtype")
+	as.BoolEqual(mediaRange("text/html", "text/html"), true, "exact")
+	as.BoolEqual(mediaRange("text/*", "textual/html"), false, "prefix only")
+	as.BoolEqual(mediaRange("text/plain", "text/html"), false, "another")
+}
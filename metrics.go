@@ -0,0 +1,222 @@
+package mixer
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// metrics represents the counters of the route, all fields are updated atomically.
+	metrics struct {
+		requests uint64
+		duration uint64    // sum of durations in nanoseconds
+		classes  [5]uint64 // responses by status class from 1xx to 5xx
+		buckets  []uint64  // requests by duration buckets, the last is +Inf
+		bounds   []float64 // upper bounds of buckets in seconds
+	}
+
+	// statusWriter represents the http.ResponseWriter which remembers the status code.
+	statusWriter struct {
+		http.ResponseWriter
+		code int
+	}
+)
+
+// DefaultBuckets is the default upper bounds (in seconds) of the latency histogram buckets.
+func DefaultBuckets() []float64 {
+	return []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+}
+
+// WithMetrics enables tracking of the requests count, the responses count by status class
+// and the latency histogram with the given buckets per (method, pattern) of the routes.
+// If buckets are empty DefaultBuckets are used. The metrics are exposed by MetricsHandler.
+// Must be set before the routes registration.
+func WithMetrics(buckets ...float64) Option {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets()
+	}
+
+	bounds := append([]float64(nil), buckets...)
+	sort.Float64s(bounds)
+
+	return func(mux *ServeMux) {
+		mux.buckets = bounds
+	}
+}
+
+// MetricsHandler returns the handler which exposes the metrics of the routes
+// in the Prometheus text format. The metrics must be enabled by WithMetrics.
+func (mux *ServeMux) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		bw := bufio.NewWriter(w)
+		mux.writeMetrics(bw)
+		_ = bw.Flush()
+	})
+}
+
+// writeMetrics writes the metrics of all routes in the Prometheus text format.
+func (mux *ServeMux) writeMetrics(w *bufio.Writer) {
+	type series struct {
+		labels string
+		m      *metrics
+	}
+
+	var all []series
+
+	seen := make(map[*metrics]bool)
+
+	for _, rt := range mux.routes() {
+		if rt.metrics == nil || seen[rt.metrics] {
+			continue
+		}
+
+		seen[rt.metrics] = true
+		labels := `method="` + escapeLabel(rt.method) + `",pattern="` + escapeLabel(rt.pattern) + `"`
+		all = append(all, series{labels, rt.metrics})
+	}
+
+	_, _ = w.WriteString("# HELP mixer_requests_total Total number of the requests by route.\n")
+	_, _ = w.WriteString("# TYPE mixer_requests_total counter\n")
+
+	for _, s := range all {
+		writeSample(w, "mixer_requests_total", s.labels, atomic.LoadUint64(&s.m.requests))
+	}
+
+	_, _ = w.WriteString("# HELP mixer_responses_total Total number of the responses by route and status class.\n")
+	_, _ = w.WriteString("# TYPE mixer_responses_total counter\n")
+
+	for _, s := range all {
+		for i := range s.m.classes {
+			labels := s.labels + `,code="` + strconv.Itoa(i+1) + `xx"`
+			writeSample(w, "mixer_responses_total", labels, atomic.LoadUint64(&s.m.classes[i]))
+		}
+	}
+
+	_, _ = w.WriteString("# HELP mixer_request_duration_seconds The request latencies by route.\n")
+	_, _ = w.WriteString("# TYPE mixer_request_duration_seconds histogram\n")
+
+	for _, s := range all {
+		var count uint64
+
+		for i := range s.m.buckets {
+			count += atomic.LoadUint64(&s.m.buckets[i])
+
+			le := "+Inf"
+			if i < len(s.m.bounds) {
+				le = strconv.FormatFloat(s.m.bounds[i], 'g', -1, 64)
+			}
+
+			writeSample(w, "mixer_request_duration_seconds_bucket", s.labels+`,le="`+le+`"`, count)
+		}
+
+		sum := time.Duration(atomic.LoadUint64(&s.m.duration)).Seconds()
+
+		_, _ = w.WriteString("mixer_request_duration_seconds_sum{" + s.labels + "} ")
+		_, _ = w.WriteString(strconv.FormatFloat(sum, 'g', -1, 64) + "\n")
+
+		writeSample(w, "mixer_request_duration_seconds_count", s.labels, count)
+	}
+}
+
+// writeSample writes one sample line in the Prometheus text format.
+func writeSample(w *bufio.Writer, name, labels string, v uint64) {
+	_, _ = w.WriteString(name + "{" + labels + "} " + strconv.FormatUint(v, 10) + "\n")
+}
+
+// escapeLabel escapes the label value for the Prometheus text format.
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// newMetrics returns the metrics for the route with pattern registered in h,
+// the candidates with the same pattern share the metrics. Returns nil if metrics disabled.
+func (mux *ServeMux) newMetrics(h http.Handler, pattern string) *metrics {
+	if mux.buckets == nil {
+		return nil
+	}
+
+	if cs, ok := h.(candidates); ok {
+		for _, rt := range cs {
+			if rt.pattern == pattern && rt.metrics != nil {
+				return rt.metrics
+			}
+		}
+	}
+
+	return &metrics{
+		buckets: make([]uint64, len(mux.buckets)+1),
+		bounds:  mux.buckets,
+	}
+}
+
+// observe records the request served by w and started at start.
+func (m *metrics) observe(w *statusWriter, start time.Time) {
+	d := time.Since(start)
+
+	atomic.AddUint64(&m.requests, 1)
+	atomic.AddUint64(&m.duration, uint64(d))
+
+	code := w.code
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	if class := code/100 - 1; class >= 0 && class < len(m.classes) {
+		atomic.AddUint64(&m.classes[class], 1)
+	}
+
+	i := sort.SearchFloat64s(m.bounds, d.Seconds())
+	atomic.AddUint64(&m.buckets[i], 1)
+}
+
+// WriteHeader implements the http.ResponseWriter's WriteHeader.
+func (w *statusWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements the http.ResponseWriter's Write.
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements the http.Flusher's Flush if the origin writer supports it.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.code == 0 {
+			w.code = http.StatusOK
+		}
+
+		f.Flush()
+	}
+}
+
+// Hijack implements the http.Hijacker's Hijack if the origin writer supports it.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	return h.Hijack()
+}
+
+// Unwrap returns the origin writer for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,128 @@
+package mixer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithMetrics(t *testing.T) {
+	as := Assert{t}
+
+	mux := New()
+	as.Equal(mux.buckets, []float64(nil), "disabled")
+
+	mux = New(WithMetrics())
+	as.Equal(mux.buckets, DefaultBuckets(), "default buckets")
+
+	mux = New(WithMetrics(1, 0.5))
+	as.Equal(mux.buckets, []float64{0.5, 1}, "sorted buckets")
+}
+
+func TestServeMuxNewMetrics(t *testing.T) {
+	mux := New(WithMetrics())
+	mux.Get("/a", TestHandler("json"), WithMatchers(HeaderMatcher("Accept", "application/json")))
+	mux.Get("/a", TestHandler("any"))
+	mux.Post("/a", TestHandler("post"))
+
+	cs := mux.tree.root.Children["a"].Methods[http.MethodGet].(candidates)
+	post := mux.tree.root.Children["a"].Methods[http.MethodPost].(candidates)
+
+	as := Assert{t}
+	as.PtrEqual(cs[0].metrics, cs[1].metrics, "same method and pattern")
+	as.PtrNotEqual(cs[0].metrics, post[0].metrics, "another method")
+
+	mux = New()
+	mux.Get("/a", TestHandler("a"))
+
+	cs = mux.tree.root.Children["a"].Methods[http.MethodGet].(candidates)
+	as.Equal(cs[0].metrics, (*metrics)(nil), "disabled")
+}
+
+func TestMetricsObserve(t *testing.T) {
+	mux := New(WithMetrics(1), WithRecovery())
+	mux.Get("/a/:int", TestHandler("a"))
+	mux.GetFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.GetFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	for _, url := range []string{"/a/1", "/a/2", "/b", "/c", "/d"} {
+		mux.ServeHTTP(httptest.NewRecorder(), mustReq(http.NewRequest(http.MethodGet, url, nil)))
+	}
+
+	m := func(pattern string) *metrics {
+		for _, rt := range mux.routes() {
+			if rt.pattern == pattern {
+				return rt.metrics
+			}
+		}
+
+		return nil
+	}
+
+	cases := []struct {
+		pattern string
+		count   int
+		class   int
+	}{
+		{pattern: "/a/:int", count: 2, class: 1},
+		{pattern: "/b", count: 1, class: 3},
+		{pattern: "/c", count: 1, class: 4},
+	}
+
+	for _, c := range cases {
+		t.Run(c.pattern, func(t *testing.T) {
+			got := m(c.pattern)
+
+			as := Assert{t}
+			as.IntEqual(int(got.requests), c.count, "requests")
+			as.IntEqual(int(got.classes[c.class]), c.count, "class")
+			as.IntEqual(int(got.buckets[0]), c.count, "bucket")
+			as.IntEqual(int(got.buckets[1]), 0, "+Inf bucket")
+		})
+	}
+}
+
+func TestServeMuxMetricsHandler(t *testing.T) {
+	mux := New(WithMetrics(0.1, 1))
+	mux.Get(`/a/"b"`, TestHandler("a"))
+	mux.Post("/c", TestHandler("c"))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, mustReq(http.NewRequest(http.MethodGet, `/a/"b"`, nil)))
+
+	w = httptest.NewRecorder()
+	mux.MetricsHandler().ServeHTTP(w, mustReq(http.NewRequest(http.MethodGet, "/metrics", nil)))
+
+	body := w.Body.String()
+	want := []string{
+		"# TYPE mixer_requests_total counter\n",
+		`mixer_requests_total{method="GET",pattern="/a/\"b\""} 1` + "\n",
+		`mixer_requests_total{method="POST",pattern="/c"} 0` + "\n",
+		`mixer_responses_total{method="GET",pattern="/a/\"b\"",code="2xx"} 1` + "\n",
+		`mixer_responses_total{method="GET",pattern="/a/\"b\"",code="5xx"} 0` + "\n",
+		"# TYPE mixer_request_duration_seconds histogram\n",
+		`mixer_request_duration_seconds_bucket{method="GET",pattern="/a/\"b\"",le="0.1"} 1` + "\n",
+		`mixer_request_duration_seconds_bucket{method="GET",pattern="/a/\"b\"",le="1"} 1` + "\n",
+		`mixer_request_duration_seconds_bucket{method="GET",pattern="/a/\"b\"",le="+Inf"} 1` + "\n",
+		`mixer_request_duration_seconds_count{method="GET",pattern="/a/\"b\""} 1` + "\n",
+		`mixer_request_duration_seconds_sum{method="POST",pattern="/c"} 0` + "\n",
+	}
+
+	as := Assert{t}
+	as.StrEqual(w.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8", "content type")
+
+	for _, line := range want {
+		as.BoolEqual(strings.Contains(body, line), true, "contains "+line)
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	as := Assert{t}
+	as.StrEqual(escapeLabel("a\\b\"c\nd"), `a\\b\"c\nd`, "escapeLabel() got")
+}
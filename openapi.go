@@ -0,0 +1,400 @@
+package mixer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// openAPIVersion is the version of the generated OpenAPI documents.
+const openAPIVersion = "3.0.3"
+
+type (
+	// OpenAPI represents the OpenAPI 3 document, only the parts used by ServeMux.
+	OpenAPI struct {
+		OpenAPI string                      `json:"openapi"`
+		Info    OpenAPIInfo                 `json:"info"`
+		Paths   map[string]*OpenAPIPathItem `json:"paths"`
+	}
+
+	// OpenAPIInfo represents the metadata of the API.
+	OpenAPIInfo struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	}
+
+	// OpenAPIPathItem represents the operations of the path.
+	OpenAPIPathItem struct {
+		Get     *OpenAPIOperation `json:"get,omitempty"`
+		Put     *OpenAPIOperation `json:"put,omitempty"`
+		Post    *OpenAPIOperation `json:"post,omitempty"`
+		Delete  *OpenAPIOperation `json:"delete,omitempty"`
+		Options *OpenAPIOperation `json:"options,omitempty"`
+		Head    *OpenAPIOperation `json:"head,omitempty"`
+		Patch   *OpenAPIOperation `json:"patch,omitempty"`
+		Trace   *OpenAPIOperation `json:"trace,omitempty"`
+	}
+
+	// OpenAPIOperation represents the operation of the path.
+	OpenAPIOperation struct {
+		OperationID string                     `json:"operationId,omitempty"`
+		Summary     string                     `json:"summary,omitempty"`
+		Servers     []OpenAPIServer            `json:"servers,omitempty"`
+		Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+		Responses   map[string]OpenAPIResponse `json:"responses"`
+	}
+
+	// OpenAPIServer represents the server of the operation, it is set for the routes with host.
+	OpenAPIServer struct {
+		URL       string                           `json:"url"`
+		Variables map[string]OpenAPIServerVariable `json:"variables,omitempty"`
+	}
+
+	// OpenAPIServerVariable represents the host path param.
+	OpenAPIServerVariable struct {
+		Default     string `json:"default"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// OpenAPIParameter represents the path param.
+	OpenAPIParameter struct {
+		Name     string        `json:"name"`
+		In       string        `json:"in"`
+		Required bool          `json:"required"`
+		Schema   OpenAPISchema `json:"schema"`
+	}
+
+	// OpenAPISchema represents the schema of the path param.
+	OpenAPISchema struct {
+		Type string `json:"type"`
+	}
+
+	// OpenAPIResponse represents the response of the operation.
+	OpenAPIResponse struct {
+		Description string `json:"description"`
+	}
+
+	// openAPISpecOp represents the operation of the OpenAPI document for validation.
+	openAPISpecOp struct {
+		path   string
+		names  []string // path params names in order of the path
+		params []OpenAPIParameter
+	}
+)
+
+var (
+	// ErrNotRegistered signals that the operation of the OpenAPI document is not registered.
+	ErrNotRegistered = errors.New("not registered")
+
+	// ErrNotDocumented signals that the route is not in the OpenAPI document.
+	ErrNotDocumented = errors.New("not documented")
+
+	// ErrSchemaMismatch signals that the path param converter disagrees with the schema.
+	ErrSchemaMismatch = errors.New("path param schema mismatch")
+)
+
+// WithSummary sets the summary of the route for the OpenAPI document.
+func WithSummary(summary string) RouteOption {
+	return func(rt *route) {
+		rt.summary = summary
+	}
+}
+
+// OpenAPI returns the OpenAPI 3 document of the registered routes.
+// The path params are named by their index in PathParams, e.g. `/a/:int` is `/a/{p0}`,
+// the host params are the server variables of the operation. The route name is
+// the operation id. CONNECT and custom methods are skipped because OpenAPI does not support them.
+// Only the first route of the same method and path is described.
+func (mux *ServeMux) OpenAPI(title, version string) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI: openAPIVersion,
+		Info:    OpenAPIInfo{Title: title, Version: version},
+		Paths:   make(map[string]*OpenAPIPathItem),
+	}
+
+	for _, rt := range mux.routes() {
+		host, path := splitPattern(rt.pattern)
+		path, params := openAPIPath(path, strings.Count(host, typeToken))
+
+		item := doc.Paths[path]
+		if item == nil {
+			item = &OpenAPIPathItem{}
+		}
+
+		op := item.operation(rt.method)
+		if op == nil {
+			continue
+		}
+
+		doc.Paths[path] = item
+
+		if *op != nil {
+			if (*op).Summary == "" {
+				(*op).Summary = rt.summary
+			}
+
+			continue
+		}
+
+		*op = &OpenAPIOperation{
+			OperationID: rt.name,
+			Summary:     rt.summary,
+			Parameters:  params,
+			Responses:   map[string]OpenAPIResponse{"default": {Description: "Default response"}},
+		}
+
+		if host != "" {
+			(*op).Servers = []OpenAPIServer{openAPIServer(host)}
+		}
+	}
+
+	return doc
+}
+
+// operation returns the pointer to the operation of method or nil if it is not supported.
+func (item *OpenAPIPathItem) operation(method string) **OpenAPIOperation {
+	switch method {
+	case http.MethodGet:
+		return &item.Get
+	case http.MethodPut:
+		return &item.Put
+	case http.MethodPost:
+		return &item.Post
+	case http.MethodDelete:
+		return &item.Delete
+	case http.MethodOptions:
+		return &item.Options
+	case http.MethodHead:
+		return &item.Head
+	case http.MethodPatch:
+		return &item.Patch
+	case http.MethodTrace:
+		return &item.Trace
+	default:
+		return nil
+	}
+}
+
+// openAPIPath rewrites path params of path to `{p<index>}` form starting from offset.
+func openAPIPath(path string, offset int) (string, []OpenAPIParameter) {
+	var params []OpenAPIParameter
+
+	parts := strings.Split(path, pathToken)
+
+	for i, part := range parts {
+		conv, ok := openAPIParam(part)
+		if !ok {
+			continue
+		}
+
+		name := "p" + strconv.Itoa(offset+len(params))
+		parts[i] = "{" + name + "}"
+		params = append(params, OpenAPIParameter{Name: name, In: "path", Required: true, Schema: schema(conv)})
+	}
+
+	return strings.Join(parts, pathToken), params
+}
+
+// openAPIParam returns the converter name of the path param part,
+// the catch-all is the string path param.
+func openAPIParam(part string) (string, bool) {
+	switch {
+	case part == catchAllToken:
+		return "", true
+	case strings.HasPrefix(part, typeToken):
+		return part[1:], true
+	default:
+		return "", false
+	}
+}
+
+// openAPIServer returns the server of host with host params as variables.
+func openAPIServer(host string) OpenAPIServer {
+	var vars map[string]OpenAPIServerVariable
+
+	labels := strings.Split(host, hostToken)
+
+	for i, label := range labels {
+		if !strings.HasPrefix(label, typeToken) {
+			continue
+		}
+
+		if vars == nil {
+			vars = make(map[string]OpenAPIServerVariable)
+		}
+
+		name := "p" + strconv.Itoa(len(vars))
+		labels[i] = "{" + name + "}"
+		vars[name] = OpenAPIServerVariable{Default: name, Description: "host param of type " + schema(label[1:]).Type}
+	}
+
+	return OpenAPIServer{URL: "//" + strings.Join(labels, hostToken), Variables: vars}
+}
+
+// schema returns the schema of the path param with converter name.
+func schema(name string) OpenAPISchema {
+	if name == "int" {
+		return OpenAPISchema{Type: "integer"}
+	}
+
+	return OpenAPISchema{Type: "string"}
+}
+
+// ValidateOpenAPI checks the registered routes against the OpenAPI 3 JSON document.
+// The paths are compared regardless of the params names and the hosts.
+// Returns ServeMuxErrors with ErrNotRegistered for the operations of the document
+// which are not registered, ErrNotDocumented for the routes not in the document and
+// ErrSchemaMismatch for the path params which converter disagrees with the schema type.
+// The error of decoding the document is returned as is.
+func (mux *ServeMux) ValidateOpenAPI(r io.Reader) error {
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return err
+	}
+
+	// spec is the operations of the document by the method and the path without params names
+	spec := make(map[string]map[string]openAPISpecOp)
+
+	for path, item := range doc.Paths {
+		var common []OpenAPIParameter
+
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &common); err != nil {
+				return err
+			}
+		}
+
+		for method, raw := range item {
+			method = strings.ToUpper(method)
+			if (&OpenAPIPathItem{}).operation(method) == nil {
+				continue
+			}
+
+			var op OpenAPIOperation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return err
+			}
+
+			key, names := openAPIKey(path)
+			if spec[key] == nil {
+				spec[key] = make(map[string]openAPISpecOp)
+			}
+
+			// full slice expression prevents sharing of the common params between operations
+			params := append(common[:len(common):len(common)], op.Parameters...)
+			spec[key][method] = openAPISpecOp{path, names, params}
+		}
+	}
+
+	var errs ServeMuxErrors
+
+	seen := make(map[string]bool)
+
+	for _, rt := range mux.routes() {
+		if (&OpenAPIPathItem{}).operation(rt.method) == nil {
+			continue
+		}
+
+		_, path := splitPattern(rt.pattern)
+		key, _ := openAPIKey(path)
+
+		if seen[rt.method+" "+key] {
+			continue
+		}
+
+		seen[rt.method+" "+key] = true
+
+		op, ok := spec[key][rt.method]
+		if !ok {
+			errs = append(errs, &ServeMuxError{rt.method, rt.pattern, ErrNotDocumented})
+			continue
+		}
+
+		errs = append(errs, op.check(rt, path)...)
+	}
+
+	var missing ServeMuxErrors
+
+	for key, ops := range spec {
+		for method, op := range ops {
+			if !seen[method+" "+key] {
+				missing = append(missing, &ServeMuxError{method, op.path, ErrNotRegistered})
+			}
+		}
+	}
+
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].pattern != missing[j].pattern {
+			return missing[i].pattern < missing[j].pattern
+		}
+
+		return missing[i].method < missing[j].method
+	})
+
+	if errs = append(errs, missing...); len(errs) != 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// check returns the errors of the path params of rt which types disagree with op.
+func (op openAPISpecOp) check(rt *route, path string) ServeMuxErrors {
+	var errs ServeMuxErrors
+
+	i := 0
+
+	for _, part := range strings.Split(path, pathToken) {
+		conv, ok := openAPIParam(part)
+		if !ok {
+			continue
+		}
+
+		name := op.names[i]
+		i++
+
+		for _, p := range op.params {
+			if p.In != "path" || p.Name != name || p.Schema.Type == "" {
+				continue
+			}
+
+			if got := schema(conv).Type; got != p.Schema.Type {
+				err := fmt.Errorf("%w: param %q is %s in spec, but %s", ErrSchemaMismatch, name, p.Schema.Type, got)
+				errs = append(errs, &ServeMuxError{rt.method, rt.pattern, err})
+			}
+		}
+	}
+
+	return errs
+}
+
+// openAPIKey returns the path with params replaced by `{}` and the params names.
+// The pattern path params are replaced too, so the key is the same for both.
+func openAPIKey(path string) (string, []string) {
+	var names []string
+
+	parts := strings.Split(path, pathToken)
+
+	for i, part := range parts {
+		switch {
+		case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+			names = append(names, part[1:len(part)-1])
+		case part == catchAllToken || strings.HasPrefix(part, typeToken):
+			names = append(names, part[1:])
+		default:
+			continue
+		}
+
+		parts[i] = "{}"
+	}
+
+	return strings.Join(parts, pathToken), names
+}
@@ -0,0 +1,247 @@
+package mixer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestServeMuxOpenAPI(t *testing.T) {
+	mux := New()
+	mux.Get("/users/:int/posts/:str", TestHandler("posts"), WithName("posts"), WithSummary("List posts"))
+	mux.Delete("/users/:int/posts/:str", TestHandler("delete"))
+	mux.Get("/files/:", TestHandler("file"), WithMatchers(QueryMatcher("v", "2")))
+	mux.Get("/files/:", TestHandler("file"), WithSummary("Get file"))
+	mux.Connect("/tunnel", TestHandler("tunnel"))
+	mux.Get(":str.example.org/a/:int/", TestHandler("host"))
+
+	got, err := json.MarshalIndent(mux.OpenAPI("API", "1.0"), "", "\t")
+	must(err)
+
+	want := `{
+	"openapi": "3.0.3",
+	"info": {
+		"title": "API",
+		"version": "1.0"
+	},
+	"paths": {
+		"/a/{p1}/": {
+			"get": {
+				"servers": [
+					{
+						"url": "//{p0}.example.org",
+						"variables": {
+							"p0": {
+								"default": "p0",
+								"description": "host param of type string"
+							}
+						}
+					}
+				],
+				"parameters": [
+					{
+						"name": "p1",
+						"in": "path",
+						"required": true,
+						"schema": {
+							"type": "integer"
+						}
+					}
+				],
+				"responses": {
+					"default": {
+						"description": "Default response"
+					}
+				}
+			}
+		},
+		"/files/{p0}": {
+			"get": {
+				"summary": "Get file",
+				"parameters": [
+					{
+						"name": "p0",
+						"in": "path",
+						"required": true,
+						"schema": {
+							"type": "string"
+						}
+					}
+				],
+				"responses": {
+					"default": {
+						"description": "Default response"
+					}
+				}
+			}
+		},
+		"/users/{p0}/posts/{p1}": {
+			"get": {
+				"operationId": "posts",
+				"summary": "List posts",
+				"parameters": [
+					{
+						"name": "p0",
+						"in": "path",
+						"required": true,
+						"schema": {
+							"type": "integer"
+						}
+					},
+					{
+						"name": "p1",
+						"in": "path",
+						"required": true,
+						"schema": {
+							"type": "string"
+						}
+					}
+				],
+				"responses": {
+					"default": {
+						"description": "Default response"
+					}
+				}
+			},
+			"delete": {
+				"parameters": [
+					{
+						"name": "p0",
+						"in": "path",
+						"required": true,
+						"schema": {
+							"type": "integer"
+						}
+					},
+					{
+						"name": "p1",
+						"in": "path",
+						"required": true,
+						"schema": {
+							"type": "string"
+						}
+					}
+				],
+				"responses": {
+					"default": {
+						"description": "Default response"
+					}
+				}
+			}
+		}
+	}
+}`
+
+	as := Assert{t}
+	as.StrEqual(string(got), want, "ServeMux.OpenAPI() got")
+	as.Equal((&OpenAPIPathItem{}).operation(http.MethodConnect), (**OpenAPIOperation)(nil), "CONNECT operation")
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	cases := []struct {
+		name   string
+		path   string
+		offset int
+		want   string
+		params []OpenAPIParameter
+	}{
+		{
+			name: "static",
+			path: "/a/b/",
+			want: "/a/b/",
+		},
+		{
+			name:   "typed params with offset",
+			path:   "/a/:int/:",
+			offset: 1,
+			want:   "/a/{p1}/{p2}",
+			params: []OpenAPIParameter{
+				{Name: "p1", In: "path", Required: true, Schema: OpenAPISchema{Type: "integer"}},
+				{Name: "p2", In: "path", Required: true, Schema: OpenAPISchema{Type: "string"}},
+			},
+		},
+		{
+			name: "catch-all",
+			path: "/static/:int/*",
+			want: "/static/{p0}/{p1}",
+			params: []OpenAPIParameter{
+				{Name: "p0", In: "path", Required: true, Schema: OpenAPISchema{Type: "integer"}},
+				{Name: "p1", In: "path", Required: true, Schema: OpenAPISchema{Type: "string"}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, params := openAPIPath(c.path, c.offset)
+
+			as := Assert{t}
+			as.StrEqual(got, c.want, "openAPIPath() got")
+			as.Equal(params, c.params, "openAPIPath() params")
+		})
+	}
+}
+
+func TestServeMuxValidateOpenAPI(t *testing.T) {
+	mux := New()
+	mux.Get("/users/:int", TestHandler("user"))
+	mux.Put("/users/:int", TestHandler("put"))
+	mux.Get("/orders/:str", TestHandler("order"))
+	mux.Get("api.example.org/users/:int/posts", TestHandler("posts"))
+	mux.Post("/orders", TestHandler("order"))
+	mux.Connect("/tunnel", TestHandler("tunnel"))
+	mux.Get("/static/*", TestHandler("static"))
+
+	spec := `{
+		"openapi": "3.0.3",
+		"paths": {
+			"/users/{id}": {
+				"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+				"get": {"responses": {}},
+				"put": {"responses": {}},
+				"delete": {"responses": {}}
+			},
+			"/users/{id}/posts": {
+				"summary": "Posts",
+				"get": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+					"responses": {}
+				}
+			},
+			"/orders/{id}": {
+				"get": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+					"responses": {}
+				}
+			},
+			"/static/{path}": {
+				"get": {
+					"parameters": [{"name": "path", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {}
+				}
+			},
+			"/health": {"get": {"responses": {}}}
+		}
+	}`
+
+	want := ServeMuxErrors{
+		{http.MethodPost, "/orders", ErrNotDocumented},
+		{http.MethodGet, "/orders/:str", errors.New(`path param schema mismatch: param "id" is integer in spec, but string`)},
+		{http.MethodGet, "/health", ErrNotRegistered},
+		{http.MethodDelete, "/users/{id}", ErrNotRegistered},
+	}
+
+	err := mux.ValidateOpenAPI(strings.NewReader(spec))
+
+	as := Assert{t}
+	as.StrEqual(err.Error(), want.Error(), "ServeMux.ValidateOpenAPI() error")
+	as.BoolEqual(errors.Is(err.(ServeMuxErrors)[1], ErrSchemaMismatch), true, "errors.Is() mismatch")
+
+	err = New().ValidateOpenAPI(strings.NewReader(`{"paths": {}}`))
+	as.Equal(err, nil, "ServeMux.ValidateOpenAPI() empty")
+
+	_, ok := New().ValidateOpenAPI(strings.NewReader(`{`)).(ServeMuxErrors)
+	as.BoolEqual(ok, false, "ServeMux.ValidateOpenAPI() decoding error")
+}
@@ -0,0 +1,32 @@
+package mixer
+
+// Option configures the ServeMux on creation.
+type Option func(*ServeMux)
+
+// WithRawPathParams disables percent-decoding of the path parts:
+// the static parts of the requests and patterns are matched and path params are converted as is.
+// Can be useful for legacy handlers which decode params by themselves.
+func WithRawPathParams() Option {
+	return func(mux *ServeMux) {
+		mux.raw = true
+	}
+}
+
+// WithCaseInsensitive enables case-insensitive matching of the static path parts.
+// If redirect is true the request which differs from the registered pattern
+// is redirected to the canonical form, e.g. `/Catalog/` to `/catalog/`.
+func WithCaseInsensitive(redirect bool) Option {
+	return func(mux *ServeMux) {
+		mux.fold = true
+		mux.redirect = redirect
+	}
+}
+
+// WithNormalizer sets the Unicode normalization function, e.g. norm.NFC.String
+// from golang.org/x/text/unicode/norm. It is applied to the static parts of
+// the patterns on registration and to the request parts on lookup.
+func WithNormalizer(normalize func(string) string) Option {
+	return func(mux *ServeMux) {
+		mux.normalize = normalize
+	}
+}
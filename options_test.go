@@ -0,0 +1,32 @@
+package mixer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithRawPathParams(t *testing.T) {
+	as := Assert{t}
+	as.BoolEqual(New().raw, false, "default")
+	as.BoolEqual(New(WithRawPathParams()).raw, true, "with option")
+}
+
+func TestWithCaseInsensitive(t *testing.T) {
+	mux := New(WithCaseInsensitive(true))
+
+	as := Assert{t}
+	as.BoolEqual(mux.fold, true, "fold")
+	as.BoolEqual(mux.redirect, true, "redirect")
+
+	mux = New(WithCaseInsensitive(false))
+
+	as.BoolEqual(mux.fold, true, "fold without redirect")
+	as.BoolEqual(mux.redirect, false, "without redirect")
+}
+
+func TestWithNormalizer(t *testing.T) {
+	mux := New(WithNormalizer(strings.ToUpper))
+
+	as := Assert{t}
+	as.StrEqual(mux.normalize("abc"), "ABC", "normalize")
+}
@@ -0,0 +1,123 @@
+package mixer
+
+import "errors"
+
+var (
+	// ErrPathParamIndex signals that path param with given index not exist.
+	ErrPathParamIndex = errors.New("path param not exist")
+
+	// ErrPathParamType signals that path param has unexpected type.
+	ErrPathParamType = errors.New("wrong path param type")
+)
+
+// Value returns the path param by index i or error if it not exist.
+func (p PathParams) Value(i int) (interface{}, error) {
+	val, ok := p[i]
+	if !ok {
+		return nil, paramError(i, ErrPathParamIndex)
+	}
+
+	return val, nil
+}
+
+// Int returns the path param by index i as int.
+func (p PathParams) Int(i int) (int, error) {
+	val, err := p.Value(i)
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := val.(type) {
+	case int:
+		return v, nil
+	case int64:
+		if int64(int(v)) == v {
+			return int(v), nil
+		}
+	}
+
+	return 0, paramError(i, ErrPathParamType)
+}
+
+// Int64 returns the path param by index i as int64.
+func (p PathParams) Int64(i int) (int64, error) {
+	val, err := p.Value(i)
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := val.(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	}
+
+	return 0, paramError(i, ErrPathParamType)
+}
+
+// String returns the path param by index i as string.
+func (p PathParams) String(i int) (string, error) {
+	val, err := p.Value(i)
+	if err != nil {
+		return "", err
+	}
+
+	v, ok := val.(string)
+	if !ok {
+		return "", paramError(i, ErrPathParamType)
+	}
+
+	return v, nil
+}
+
+// LookupInt returns the path param by index i as int.
+// If param not exist or has another type returns zero value and false.
+func (p PathParams) LookupInt(i int) (int, bool) {
+	v, err := p.Int(i)
+	return v, err == nil
+}
+
+// LookupInt64 returns the path param by index i as int64.
+// If param not exist or has another type returns zero value and false.
+func (p PathParams) LookupInt64(i int) (int64, bool) {
+	v, err := p.Int64(i)
+	return v, err == nil
+}
+
+// LookupString returns the path param by index i as string.
+// If param not exist or has another type returns zero value and false.
+func (p PathParams) LookupString(i int) (string, bool) {
+	v, err := p.String(i)
+	return v, err == nil
+}
+
+// MustInt is like Int but panics with *ServeMuxError if param is invalid.
+func (p PathParams) MustInt(i int) int {
+	v, err := p.Int(i)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// MustInt64 is like Int64 but panics with *ServeMuxError if param is invalid.
+func (p PathParams) MustInt64(i int) int64 {
+	v, err := p.Int64(i)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// MustString is like String but panics with *ServeMuxError if param is invalid.
+func (p PathParams) MustString(i int) string {
+	v, err := p.String(i)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
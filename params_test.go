@@ -0,0 +1,206 @@
+package mixer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPathParamsValue(t *testing.T) {
+	params := PathParams{0: 12}
+
+	got, err := params.Value(0)
+
+	as := Assert{t}
+	as.Equal(got, 12, "exist got")
+	as.Equal(err, nil, "exist error")
+
+	got, err = params.Value(1)
+
+	as.Equal(got, nil, "not exist got")
+	as.Equal(err, paramError(1, ErrPathParamIndex), "not exist error")
+}
+
+func TestPathParamsInt(t *testing.T) {
+	cases := []struct {
+		name   string
+		params PathParams
+		want   int
+		err    error
+	}{
+		{
+			name:   "int",
+			params: PathParams{0: 12},
+			want:   12,
+			err:    nil,
+		},
+		{
+			name:   "int64",
+			params: PathParams{0: int64(12)},
+			want:   12,
+			err:    nil,
+		},
+		{
+			name:   "string",
+			params: PathParams{0: "12"},
+			want:   0,
+			err:    paramError(0, ErrPathParamType),
+		},
+		{
+			name:   "not exist",
+			params: PathParams{1: 12},
+			want:   0,
+			err:    paramError(0, ErrPathParamIndex),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.params.Int(0)
+
+			as := Assert{t}
+			as.IntEqual(got, c.want, "PathParams.Int() got")
+			as.Equal(err, c.err, "PathParams.Int() error")
+
+			got, ok := c.params.LookupInt(0)
+
+			as.IntEqual(got, c.want, "PathParams.LookupInt() got")
+			as.BoolEqual(ok, c.err == nil, "PathParams.LookupInt() ok")
+		})
+	}
+}
+
+func TestPathParamsInt64(t *testing.T) {
+	cases := []struct {
+		name   string
+		params PathParams
+		want   int64
+		err    error
+	}{
+		{
+			name:   "int",
+			params: PathParams{0: 12},
+			want:   12,
+			err:    nil,
+		},
+		{
+			name:   "int64",
+			params: PathParams{0: int64(1) << 40},
+			want:   1 << 40,
+			err:    nil,
+		},
+		{
+			name:   "string",
+			params: PathParams{0: "12"},
+			want:   0,
+			err:    paramError(0, ErrPathParamType),
+		},
+		{
+			name:   "not exist",
+			params: nil,
+			want:   0,
+			err:    paramError(0, ErrPathParamIndex),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.params.Int64(0)
+
+			as := Assert{t}
+			as.Equal(got, c.want, "PathParams.Int64() got")
+			as.Equal(err, c.err, "PathParams.Int64() error")
+
+			got, ok := c.params.LookupInt64(0)
+
+			as.Equal(got, c.want, "PathParams.LookupInt64() got")
+			as.BoolEqual(ok, c.err == nil, "PathParams.LookupInt64() ok")
+		})
+	}
+}
+
+func TestPathParamsString(t *testing.T) {
+	cases := []struct {
+		name   string
+		params PathParams
+		want   string
+		err    error
+	}{
+		{
+			name:   "string",
+			params: PathParams{0: "abc"},
+			want:   "abc",
+			err:    nil,
+		},
+		{
+			name:   "int",
+			params: PathParams{0: 12},
+			want:   "",
+			err:    paramError(0, ErrPathParamType),
+		},
+		{
+			name:   "not exist",
+			params: PathParams{},
+			want:   "",
+			err:    paramError(0, ErrPathParamIndex),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.params.String(0)
+
+			as := Assert{t}
+			as.StrEqual(got, c.want, "PathParams.String() got")
+			as.Equal(err, c.err, "PathParams.String() error")
+
+			got, ok := c.params.LookupString(0)
+
+			as.StrEqual(got, c.want, "PathParams.LookupString() got")
+			as.BoolEqual(ok, c.err == nil, "PathParams.LookupString() ok")
+		})
+	}
+}
+
+func TestPathParamsMust(t *testing.T) {
+	params := PathParams{0: 12, 1: "abc"}
+
+	as := Assert{t}
+	as.IntEqual(params.MustInt(0), 12, "PathParams.MustInt() got")
+	as.Equal(params.MustInt64(0), int64(12), "PathParams.MustInt64() got")
+	as.StrEqual(params.MustString(1), "abc", "PathParams.MustString() got")
+
+	cases := []struct {
+		name string
+		fn   func()
+		want error
+	}{
+		{
+			name: "MustInt",
+			fn:   func() { params.MustInt(1) },
+			want: ErrPathParamType,
+		},
+		{
+			name: "MustInt64",
+			fn:   func() { params.MustInt64(2) },
+			want: ErrPathParamIndex,
+		},
+		{
+			name: "MustString",
+			fn:   func() { params.MustString(0) },
+			want: ErrPathParamType,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				err, ok := recover().(*ServeMuxError)
+				if !ok || !errors.Is(err, c.want) {
+					t.Errorf("PathParams.%s() got = %v, want = %v", c.name, err, c.want)
+				}
+			}()
+
+			c.fn()
+		})
+	}
+}
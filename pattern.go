@@ -0,0 +1,224 @@
+package mixer
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// ParsedPattern represents the syntax tree of the pattern.
+	ParsedPattern struct {
+		// Host is the host labels in the pattern order, empty if the pattern has no host.
+		Host []Segment
+
+		// Path is the path parts, the trailing slash is the last one.
+		Path []Segment
+	}
+
+	// Segment represents the host label or the path part of the pattern.
+	Segment struct {
+		Kind NodeKind
+
+		// Value is the text of the segment, e.g. `catalog`, `:int`, `*` or `/`.
+		Value string
+
+		// Offset is the byte offset of the segment in the pattern.
+		Offset int
+	}
+
+	// PatternError represents the syntax error of the pattern at byte offset.
+	// It wraps ErrPattern or ErrPathParam for the unknown converter.
+	PatternError struct {
+		Offset int
+		Msg    string
+
+		// Hint is the suggestion for fixing, e.g. `did you mean 'int'?`.
+		Hint string
+
+		err error
+	}
+)
+
+// Error implements the error's Error.
+func (e *PatternError) Error() string {
+	msg := e.Msg + " at offset " + strconv.Itoa(e.Offset)
+	if e.Hint != "" {
+		msg += ", " + e.Hint
+	}
+
+	return msg
+}
+
+// Unwrap implements the error's Unwrap.
+func (e *PatternError) Unwrap() error {
+	return e.err
+}
+
+// ParsePattern parses the pattern with the converters of the ServeMux.
+// Returns *PatternError with the byte offset of the problem.
+func (mux *ServeMux) ParsePattern(pattern string) (*ParsedPattern, error) {
+	host, path := splitPattern(pattern)
+	if !strings.HasPrefix(path, pathToken) {
+		return nil, &PatternError{len(host), "missing path", "the path must start with '/'", ErrPattern}
+	}
+
+	p := &ParsedPattern{}
+
+	if host != "" {
+		off := 0
+
+		for _, label := range strings.Split(host, hostToken) {
+			if label == "" {
+				return nil, &PatternError{off, "empty host label", "", ErrPattern}
+			}
+
+			if label == catchAllToken {
+				return nil, &PatternError{off, "catch-all in host", "", ErrPattern}
+			}
+
+			s, err := mux.parseSegment(label, off)
+			if err != nil {
+				return nil, err
+			}
+
+			p.Host = append(p.Host, s)
+			off += len(label) + len(hostToken)
+		}
+	}
+
+	off := len(host) + len(pathToken)
+	parts := strings.Split(path[len(pathToken):], pathToken)
+
+	for i, part := range parts {
+		switch {
+		case part == "" && i == len(parts)-1:
+			p.Path = append(p.Path, Segment{SlashNode, pathToken, off - len(pathToken)})
+		case part == "":
+			return nil, &PatternError{off, "empty path part", "", ErrPattern}
+		case part == catchAllToken && i == len(parts)-1:
+			p.Path = append(p.Path, Segment{CatchAllNode, part, off})
+		case part == catchAllToken:
+			return nil, &PatternError{off, "catch-all is not the last path part", "", ErrPattern}
+		default:
+			s, err := mux.parseSegment(part, off)
+			if err != nil {
+				return nil, err
+			}
+
+			p.Path = append(p.Path, s)
+		}
+
+		off += len(part) + len(pathToken)
+	}
+
+	return p, nil
+}
+
+// parseSegment returns the static or path param segment of text at offset.
+func (mux *ServeMux) parseSegment(text string, off int) (Segment, error) {
+	if !strings.HasPrefix(text, typeToken) {
+		if _, err := url.PathUnescape(text); err != nil {
+			esc := string(err.(url.EscapeError))
+			off += strings.Index(text, esc)
+
+			return Segment{}, &PatternError{off, "invalid escape " + quote(esc), "", ErrPattern}
+		}
+
+		return Segment{StaticNode, text, off}, nil
+	}
+
+	name := text[len(typeToken):]
+	if mux.converters[name] != nil {
+		return Segment{ParamNode, text, off}, nil
+	}
+
+	err := &PatternError{off, "unknown converter " + quote(name), "", ErrPathParam}
+	if s := mux.suggest(name); s != "" {
+		err.Hint = "did you mean " + quote(s) + "?"
+	}
+
+	return Segment{}, err
+}
+
+// suggest returns the closest converter name to name or empty string if no one is close enough.
+func (mux *ServeMux) suggest(name string) string {
+	names := make([]string, 0, len(mux.converters))
+	for n := range mux.converters {
+		if n != "" {
+			names = append(names, n)
+		}
+	}
+
+	sort.Strings(names)
+
+	// the distance of the typo is less than a half of the name
+	best, limit := "", len(name)/2+1
+
+	for _, n := range names {
+		if d := distance(name, n); d < limit {
+			best, limit = n, d
+		}
+	}
+
+	return best
+}
+
+// parts returns the copies of the path segments and the reversed host segments
+// in form of the tree segments.
+func (p *ParsedPattern) parts() ([]Segment, []Segment) {
+	parts := append([]Segment(nil), p.Path...)
+	labels := make([]Segment, 0, len(p.Host))
+
+	for i := len(p.Host) - 1; i >= 0; i-- {
+		label := p.Host[i]
+		if label.Kind == StaticNode {
+			label.Value = strings.ToLower(label.Value) // host is case-insensitive
+		}
+
+		labels = append(labels, label)
+	}
+
+	return parts, labels
+}
+
+// distance returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+
+		prev = curr
+	}
+
+	return prev[len(b)]
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// quote returns s in single quotes.
+func quote(s string) string {
+	return "'" + s + "'"
+}
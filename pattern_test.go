@@ -0,0 +1,121 @@
+package mixer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestServeMuxParsePattern(t *testing.T) {
+	cases := []struct {
+		name    string
+		pattern string
+		want    *ParsedPattern
+		err     error
+		msg     string
+	}{
+		{
+			name:    "root",
+			pattern: "/",
+			want:    &ParsedPattern{Path: []Segment{{SlashNode, "/", 0}}},
+		},
+		{
+			name:    "host and path",
+			pattern: ":str.Example.com/catalog/:int/",
+			want: &ParsedPattern{
+				Host: []Segment{{ParamNode, ":str", 0}, {StaticNode, "Example", 5}, {StaticNode, "com", 13}},
+				Path: []Segment{{StaticNode, "catalog", 17}, {ParamNode, ":int", 25}, {SlashNode, "/", 29}},
+			},
+		},
+		{
+			name:    "default converter",
+			pattern: "/a/:",
+			want:    &ParsedPattern{Path: []Segment{{StaticNode, "a", 1}, {ParamNode, ":", 3}}},
+		},
+		{
+			name:    "unknown converter with hint",
+			pattern: "/catalog/:in",
+			err:     ErrPathParam,
+			msg:     "unknown converter 'in' at offset 9, did you mean 'int'?",
+		},
+		{
+			name:    "unknown host converter with hint",
+			pattern: ":strr.example.com/",
+			err:     ErrPathParam,
+			msg:     "unknown converter 'strr' at offset 0, did you mean 'str'?",
+		},
+		{
+			name:    "unknown converter without hint",
+			pattern: "/a/:uuid",
+			err:     ErrPathParam,
+			msg:     "unknown converter 'uuid' at offset 3",
+		},
+		{
+			name:    "empty path part",
+			pattern: "/a//b",
+			err:     ErrPattern,
+			msg:     "empty path part at offset 3",
+		},
+		{
+			name:    "invalid escape",
+			pattern: "/files/a%zz",
+			err:     ErrPattern,
+			msg:     "invalid escape '%zz' at offset 8",
+		},
+		{
+			name:    "empty host label",
+			pattern: "example..com/",
+			err:     ErrPattern,
+			msg:     "empty host label at offset 8",
+		},
+		{
+			name:    "missing path",
+			pattern: "example.com",
+			err:     ErrPattern,
+			msg:     "missing path at offset 11, the path must start with '/'",
+		},
+		{
+			name:    "relative path",
+			pattern: "catalog/",
+			err:     ErrPattern,
+			msg:     "missing path at offset 0, the path must start with '/'",
+		},
+	}
+
+	mux := New()
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := mux.ParsePattern(c.pattern)
+
+			as := Assert{t}
+			as.Equal(got, c.want, "ServeMux.ParsePattern() got")
+			as.BoolEqual(errors.Is(err, c.err), true, "ServeMux.ParsePattern() error")
+
+			if err != nil {
+				var pe *PatternError
+
+				as.BoolEqual(errors.As(err, &pe), true, "ServeMux.ParsePattern() PatternError")
+				as.StrEqual(err.Error(), c.msg, "ServeMux.ParsePattern() message")
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"in", "int", 1},
+		{"int", "str", 3},
+		{"strr", "str", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	as := Assert{t}
+
+	for _, c := range cases {
+		as.IntEqual(distance(c.a, c.b), c.want, "distance("+c.a+", "+c.b+")")
+	}
+}
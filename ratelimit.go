@@ -0,0 +1,168 @@
+package mixer
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultMaxKeys = 10000
+
+type (
+	// RateKey returns the key of the request which limits are tracked by.
+	RateKey func(r *http.Request) string
+
+	// RateLimit represents the token bucket limit of the route.
+	RateLimit struct {
+		// Rate is the number of tokens added to the bucket per second.
+		Rate float64
+
+		// Burst is the bucket size, at least one.
+		Burst int
+
+		// Key is the key of the bucket, if nil all requests share one bucket.
+		Key RateKey
+
+		// MaxKeys is the max number of the tracked buckets, the least recently
+		// used one is evicted when it is reached. Zero means 10000.
+		MaxKeys int
+	}
+
+	// limiter represents the buckets of the route in the LRU order.
+	limiter struct {
+		RateLimit
+
+		mu      sync.Mutex
+		buckets map[string]*list.Element
+		lru     *list.List
+		now     func() time.Time
+	}
+
+	// bucket represents the token bucket of one key.
+	bucket struct {
+		key    string
+		tokens float64
+		last   time.Time
+	}
+)
+
+// ParamKey returns the key of the path param with index i.
+func ParamKey(i int) RateKey {
+	return func(r *http.Request) string {
+		val, err := GetPathParams(r).Value(i)
+		if err != nil {
+			return ""
+		}
+
+		return fmt.Sprint(val)
+	}
+}
+
+// IPKey returns the key of the client IP address got from r.RemoteAddr.
+func IPKey() RateKey {
+	return func(r *http.Request) string {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return r.RemoteAddr
+		}
+
+		return host
+	}
+}
+
+// HeaderKey returns the key of the request header value.
+func HeaderKey(name string) RateKey {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// WithRateLimit limits the requests of the route by the token bucket per key,
+// e.g. 10 requests per second per `/tenants/:int` with ParamKey(0).
+// The limited requests are responded with 429 and Retry-After header.
+// The limit is applied as the route middleware at the position of the option.
+func WithRateLimit(l RateLimit) RouteOption {
+	return func(rt *route) {
+		rt.middleware = append(rt.middleware, newLimiter(l).middleware)
+	}
+}
+
+// newLimiter returns the limiter with defaults applied to l.
+func newLimiter(l RateLimit) *limiter {
+	if l.Burst < 1 {
+		l.Burst = 1
+	}
+
+	if l.MaxKeys < 1 {
+		l.MaxKeys = defaultMaxKeys
+	}
+
+	return &limiter{
+		RateLimit: l,
+		buckets:   make(map[string]*list.Element),
+		lru:       list.New(),
+		now:       time.Now,
+	}
+}
+
+// middleware responds 429 if the request is limited and calls next otherwise.
+func (l *limiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var key string
+		if l.Key != nil {
+			key = l.Key(r)
+		}
+
+		if wait := l.take(key); wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// take takes the token from the bucket of key.
+// Returns zero if it is taken or the time to wait for the token otherwise.
+func (l *limiter) take(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	var b *bucket
+
+	if e, ok := l.buckets[key]; ok {
+		l.lru.MoveToFront(e)
+		b = e.Value.(*bucket)
+		b.tokens = math.Min(float64(l.Burst), b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+		b.last = now
+	} else {
+		if l.lru.Len() >= l.MaxKeys {
+			e := l.lru.Back()
+			l.lru.Remove(e)
+			delete(l.buckets, e.Value.(*bucket).key)
+		}
+
+		b = &bucket{key: key, tokens: float64(l.Burst), last: now}
+		l.buckets[key] = l.lru.PushFront(b)
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	if l.Rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+}
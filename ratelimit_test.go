@@ -0,0 +1,72 @@
+package mixer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateKeys(t *testing.T) {
+	req := mustReq(http.NewRequest(http.MethodGet, "/", nil))
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Api-Key", "secret")
+	req = Match{Params: PathParams{0: 12}}.request(req)
+
+	as := Assert{t}
+	as.StrEqual(ParamKey(0)(req), "12", "ParamKey() got")
+	as.StrEqual(ParamKey(1)(req), "", "ParamKey() not exist")
+	as.StrEqual(IPKey()(req), "10.0.0.1", "IPKey() got")
+	as.StrEqual(HeaderKey("x-api-key")(req), "secret", "HeaderKey() got")
+
+	req.RemoteAddr = "10.0.0.1"
+	as.StrEqual(IPKey()(req), "10.0.0.1", "IPKey() without port")
+}
+
+func TestLimiterTake(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newLimiter(RateLimit{Rate: 2, Burst: 2, MaxKeys: 2})
+	l.now = func() time.Time { return now }
+
+	as := Assert{t}
+	as.IntEqual(int(l.take("a")), 0, "first")
+	as.IntEqual(int(l.take("a")), 0, "burst")
+	as.IntEqual(int(l.take("a")), int(500*time.Millisecond), "limited")
+	as.IntEqual(int(l.take("b")), 0, "another key")
+
+	now = now.Add(250 * time.Millisecond)
+	as.IntEqual(int(l.take("a")), int(250*time.Millisecond), "half token")
+
+	now = now.Add(250 * time.Millisecond)
+	as.IntEqual(int(l.take("a")), 0, "refilled")
+
+	as.IntEqual(int(l.take("c")), 0, "evicts least recently used")
+	as.IntEqual(l.lru.Len(), 2, "bounded")
+
+	_, ok := l.buckets["b"]
+	as.BoolEqual(ok, false, "evicted")
+}
+
+func TestWithRateLimit(t *testing.T) {
+	mux := New()
+	mux.Get("/tenants/:int", TestHandler("tenant"), WithRateLimit(RateLimit{Rate: 0.5, Key: ParamKey(0)}))
+
+	cases := []struct {
+		url        string
+		code       int
+		retryAfter string
+	}{
+		{url: "/tenants/1", code: http.StatusOK, retryAfter: ""},
+		{url: "/tenants/1", code: http.StatusTooManyRequests, retryAfter: "2"},
+		{url: "/tenants/2", code: http.StatusOK, retryAfter: ""},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, mustReq(http.NewRequest(http.MethodGet, c.url, nil)))
+
+		as := Assert{t}
+		as.IntEqual(w.Code, c.code, c.url+" code")
+		as.StrEqual(w.Header().Get("Retry-After"), c.retryAfter, c.url+" Retry-After")
+	}
+}
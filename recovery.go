@@ -0,0 +1,92 @@
+package mixer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+// PanicError represents the recovered panic of the handler with the matched route.
+type PanicError struct {
+	Method  string
+	Pattern string
+	Params  PathParams
+	Value   interface{}
+	Stack   []byte
+}
+
+// ErrPanic signals that the handler panicked, use errors.Is for checking.
+var ErrPanic = errors.New("handler panic")
+
+// Error implements the error's Error.
+func (e *PanicError) Error() string {
+	keys := make([]int, 0, len(e.Params))
+	for k := range e.Params {
+		keys = append(keys, k)
+	}
+
+	sort.Ints(keys)
+
+	params := make([]string, 0, len(keys))
+	for _, k := range keys {
+		params = append(params, fmt.Sprintf("%d=%v", k, e.Params[k]))
+	}
+
+	return fmt.Sprintf("httpmux: handler (%s) %s [%s] panic: %v",
+		e.Method, e.Pattern, strings.Join(params, " "), e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Is reports whether target is ErrPanic.
+func (e *PanicError) Is(target error) bool {
+	return target == ErrPanic
+}
+
+// WithRecovery enables recovering of the handler panics. The panic is reported
+// as *PanicError to the error handler (see WithErrorHandler) which logs it with
+// the stack and replies with 500 by default unless the response has already started.
+// The http.ErrAbortHandler is not recovered to abort the response.
+func WithRecovery() Option {
+	return func(mux *ServeMux) {
+		mux.recovery = true
+	}
+}
+
+// WithErrorHandler sets the handler of the errors: not matched requests
+// (see Match for the possible errors) and recovered panics.
+func WithErrorHandler(fn func(w http.ResponseWriter, r *http.Request, err error)) Option {
+	return func(mux *ServeMux) {
+		mux.errorHandler = fn
+	}
+}
+
+// recover recovers the panic and reports it with the matched route to the error handler.
+// Must be called directly by defer.
+func (mux *ServeMux) recover(w http.ResponseWriter, r *http.Request, m Match) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+
+	err := &PanicError{
+		Method:  r.Method,
+		Pattern: m.Pattern,
+		Params:  m.Params,
+		Value:   v,
+		Stack:   debug.Stack(),
+	}
+
+	mux.errorHandler(w, r, err)
+}
@@ -0,0 +1,130 @@
+package mixer
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPanicErrorError(t *testing.T) {
+	err := &PanicError{
+		Method:  http.MethodGet,
+		Pattern: "/a/:int/:str",
+		Params:  PathParams{1: "abc", 0: 12},
+		Value:   "boom",
+	}
+	want := "httpmux: handler (GET) /a/:int/:str [0=12 1=abc] panic: boom"
+
+	as := Assert{t}
+	as.StrEqual(err.Error(), want, "PanicError.Error() got")
+}
+
+func TestPanicErrorUnwrap(t *testing.T) {
+	cause := paramError(0, ErrPathParamType)
+
+	as := Assert{t}
+	as.Equal((&PanicError{Value: cause}).Unwrap(), cause, "error value")
+	as.Equal((&PanicError{Value: "boom"}).Unwrap(), nil, "not error value")
+	as.BoolEqual(errors.Is(&PanicError{Value: cause}, ErrPathParamType), true, "errors.Is() cause")
+	as.BoolEqual(errors.Is(&PanicError{Value: "boom"}, ErrPanic), true, "errors.Is() ErrPanic")
+}
+
+func TestWithErrorHandler(t *testing.T) {
+	var got error
+
+	mux := New(WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		got = err
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, mustReq(http.NewRequest(http.MethodGet, "/a", nil)))
+
+	as := Assert{t}
+	as.Equal(got, notFoundError(http.MethodGet, "/a"), "WithErrorHandler() error")
+	as.IntEqual(w.Code, http.StatusTeapot, "WithErrorHandler() code")
+}
+
+func TestWithRecovery(t *testing.T) {
+	var got *PanicError
+
+	mux := New(WithRecovery(), WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		if errors.As(err, &got) {
+			serveError(w, r, err)
+		}
+	}))
+	mux.GetFunc("/a/:int", func(w http.ResponseWriter, r *http.Request) {
+		GetPathParams(r).MustString(0)
+	})
+	mux.GetFunc("/abort", func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, mustReq(http.NewRequest(http.MethodGet, "/a/12", nil)))
+
+	as := Assert{t}
+	as.IntEqual(w.Code, http.StatusInternalServerError, "recovered code")
+	as.StrEqual(got.Method, http.MethodGet, "recovered method")
+	as.StrEqual(got.Pattern, "/a/:int", "recovered pattern")
+	as.Equal(got.Params, PathParams{0: 12}, "recovered params")
+	as.BoolEqual(errors.Is(got, ErrPathParamType), true, "recovered cause")
+	as.IntNotEqual(len(got.Stack), 0, "recovered stack")
+
+	t.Run("default error handler", func(t *testing.T) {
+		var logs bytes.Buffer
+
+		log.SetOutput(&logs)
+		defer log.SetOutput(os.Stderr)
+
+		mux := New(WithRecovery())
+		mux.GetFunc("/", func(http.ResponseWriter, *http.Request) { panic("boom") })
+		mux.GetFunc("/started", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			panic("late boom")
+		})
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, mustReq(http.NewRequest(http.MethodGet, "/", nil)))
+
+		as := Assert{t}
+		as.IntEqual(w.Code, http.StatusInternalServerError, "recovered code")
+		as.BoolEqual(strings.Contains(logs.String(), "panic: boom"), true, "logged panic")
+		as.BoolEqual(strings.Contains(logs.String(), "goroutine"), true, "logged stack")
+
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, mustReq(http.NewRequest(http.MethodGet, "/started", nil)))
+
+		as.IntEqual(w.Code, http.StatusCreated, "started code")
+		as.StrEqual(w.Body.String(), "", "started body")
+		as.BoolEqual(strings.Contains(logs.String(), "panic: late boom"), true, "logged started panic")
+	})
+
+	t.Run("abort handler", func(t *testing.T) {
+		defer func() {
+			if v := recover(); v != http.ErrAbortHandler {
+				t.Errorf("ServeMux.ServeHTTP() got = %v, want = %v", v, http.ErrAbortHandler)
+			}
+		}()
+
+		mux.ServeHTTP(httptest.NewRecorder(), mustReq(http.NewRequest(http.MethodGet, "/abort", nil)))
+	})
+
+	t.Run("without recovery", func(t *testing.T) {
+		mux := New()
+		mux.GetFunc("/", func(http.ResponseWriter, *http.Request) { panic("boom") })
+
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Errorf("ServeMux.ServeHTTP() got = %v, want = %v", v, "boom")
+			}
+		}()
+
+		mux.ServeHTTP(httptest.NewRecorder(), mustReq(http.NewRequest(http.MethodGet, "/", nil)))
+	})
+}
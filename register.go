@@ -0,0 +1,61 @@
+package mixer
+
+import (
+	"net/http"
+	"strings"
+)
+
+type (
+	// Registration represents the route for the batch registration.
+	Registration struct {
+		Method  string
+		Pattern string
+		Handler http.Handler
+		Options []RouteOption
+	}
+
+	// ServeMuxErrors represents all errors of the batch registration.
+	ServeMuxErrors []*ServeMuxError
+)
+
+// Error implements the error's Error.
+func (e ServeMuxErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Register validates the whole set of routes and registers them if all are valid.
+// Otherwise returns ServeMuxErrors with every error in the order of routes
+// and the ServeMux stays untouched. The routes are checked against each other too.
+// Every route is registered once, so its options are applied once.
+func (mux *ServeMux) Register(routes ...Registration) error {
+	var errs ServeMuxErrors
+
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+
+	paths, hosts := mux.tree, mux.hosts
+
+	for _, r := range routes {
+		p, h, err := mux.handle(paths, hosts, r.Method, r.Pattern, r.Handler, r.Options)
+		if err != nil {
+			errs = append(errs, err.(*ServeMuxError))
+			continue
+		}
+
+		paths, hosts = p, h
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	mux.tree, mux.hosts = paths, hosts
+
+	return nil
+}
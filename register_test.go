@@ -0,0 +1,86 @@
+package mixer
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestServeMuxErrorsError(t *testing.T) {
+	errs := ServeMuxErrors{
+		{"GET", "/a", errors.New("first")},
+		{"PUT", "/b", errors.New("second")},
+	}
+	want := "httpmux: handler (GET) /a error: first; httpmux: handler (PUT) /b error: second"
+
+	as := Assert{t}
+	as.StrEqual(errs.Error(), want, "ServeMuxErrors.Error() got")
+}
+
+func TestServeMuxRegister(t *testing.T) {
+	t.Run("report all errors", func(t *testing.T) {
+		mux := New()
+		mux.Get("/a/", TestHandler("a"))
+
+		exp := mux.tree.String()
+
+		err := mux.Register(
+			Registration{Method: http.MethodGet, Pattern: "/b/", Handler: TestHandler("b")},
+			Registration{Method: "invalid", Pattern: "/c/", Handler: TestHandler("c")},
+			Registration{Method: http.MethodGet, Pattern: "/a/", Handler: TestHandler("a")},
+			Registration{Method: http.MethodGet, Pattern: "/b/", Handler: TestHandler("b")},
+			Registration{Method: http.MethodGet, Pattern: "/d/:mem", Handler: TestHandler("d")},
+			Registration{Method: http.MethodGet, Pattern: "host.com/e/", Handler: nil},
+		)
+		want := ServeMuxErrors{
+			methodError("invalid", "/c/"),
+			{http.MethodGet, "/a/", &Conflict{Method: http.MethodGet, Pattern: "/a/", Segment: -1, err: ErrDuplicate}},
+			{http.MethodGet, "/b/", &Conflict{Method: http.MethodGet, Pattern: "/b/", Segment: -1, err: ErrDuplicate}},
+			{http.MethodGet, "/d/:mem", &PatternError{3, "unknown converter 'mem'", "", ErrPathParam}},
+			handlerError(http.MethodGet, "host.com/e/"),
+		}
+
+		as := Assert{t}
+		as.Equal(err, want, "ServeMux.Register() error")
+		as.StrEqual(mux.tree.String(), exp, "ServeMux.Register() tree")
+	})
+
+	t.Run("register all routes", func(t *testing.T) {
+		mux := New()
+		mux.Get("/a/", TestHandler("a"))
+
+		err := mux.Register(
+			Registration{Method: http.MethodPut, Pattern: "/a/", Handler: TestHandler("put")},
+			Registration{Method: http.MethodGet, Pattern: "host.com/b/:int", Handler: TestHandler("b"),
+				Options: []RouteOption{WithName("b")}},
+		)
+
+		as := Assert{t}
+		as.Equal(err, nil, "ServeMux.Register() error")
+
+		req := mustReq(http.NewRequest(http.MethodPut, "/a/", nil))
+		m, err := mux.Match(req)
+
+		as.Equal(err, nil, "ServeMux.Match() put error")
+		as.Equal(m.Handler, TestHandler("put"), "ServeMux.Match() put handler")
+
+		req = mustReq(http.NewRequest(http.MethodGet, "http://host.com/b/12", nil))
+		m, err = mux.Match(req)
+
+		as.Equal(err, nil, "ServeMux.Match() host error")
+		as.StrEqual(m.Name, "b", "ServeMux.Match() host name")
+	})
+
+	t.Run("apply options once", func(t *testing.T) {
+		calls := 0
+		count := func(*route) { calls++ }
+
+		mux := New()
+		err := mux.Register(Registration{Method: http.MethodGet, Pattern: "/a/", Handler: TestHandler("a"),
+			Options: []RouteOption{count}})
+
+		as := Assert{t}
+		as.Equal(err, nil, "ServeMux.Register() error")
+		as.Equal(calls, 1, "ServeMux.Register() option calls")
+	})
+}
@@ -0,0 +1,95 @@
+package mixer
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WithName sets the name of the route, it is available through Match.
+func WithName(name string) RouteOption {
+	return func(rt *route) {
+		rt.name = name
+	}
+}
+
+// WithMiddleware adds the middleware to the route, the first one is the outermost.
+// The composed handler is stored in the tree, so it is run only for this route.
+func WithMiddleware(mw ...Middleware) RouteOption {
+	return func(rt *route) {
+		rt.middleware = append(rt.middleware, mw...)
+	}
+}
+
+// Routes returns all registered routes sorted by pattern and method.
+func (mux *ServeMux) Routes() []Route {
+	rts := mux.routes()
+	routes := make([]Route, 0, len(rts))
+
+	for _, rt := range rts {
+		routes = append(routes, Route{rt.method, rt.pattern, rt.name, rt.handler})
+	}
+
+	return routes
+}
+
+// WriteTree writes the tree of the registered patterns to w, one node per line
+// indented by depth with the methods of the node. The path params are shown with
+// their converter, e.g. `:int`. The host trees are written first from the top-level
+// domain, the paths of the host are nested under `/` node of its last label.
+func (mux *ServeMux) WriteTree(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	paths, hosts := mux.trees()
+
+	for _, key := range hosts.root.keys() {
+		mux.writeNode(bw, hosts.root.Children[key], key, 0)
+	}
+
+	mux.writeNode(bw, paths.root, pathToken, 0)
+
+	return bw.Flush()
+}
+
+// writeNode writes n with key and its descendants at depth.
+// The write errors are kept by w and returned by its Flush.
+func (mux *ServeMux) writeNode(w *bufio.Writer, n *node, key string, depth int) {
+	if n.tid == param {
+		key += mux.converterName(n.conv)
+	}
+
+	_, _ = w.WriteString(strings.Repeat("  ", depth) + key)
+
+	if methods := n.methods(); len(methods) != 0 {
+		_, _ = w.WriteString(" [" + strings.Join(methods, " ") + "]")
+	}
+
+	_, _ = w.WriteString("\n")
+
+	if n.sub != nil {
+		mux.writeNode(w, n.sub.root, pathToken, depth+1)
+	}
+
+	for _, k := range n.keys() {
+		mux.writeNode(w, n.Children[k], k, depth+1)
+	}
+}
+
+// converterName returns the first sorted name of the converter, the empty alias is skipped.
+func (mux *ServeMux) converterName(conv *convert) string {
+	var names []string
+
+	for name, c := range mux.converters {
+		if c == conv && name != "" {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return ""
+	}
+
+	return names[0]
+}
@@ -0,0 +1,94 @@
+package mixer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithName(t *testing.T) {
+	rt := &route{}
+	WithName("name")(rt)
+
+	as := Assert{t}
+	as.StrEqual(rt.name, "name", "WithName() got")
+}
+
+func TestWithMiddleware(t *testing.T) {
+	mw := func(h http.Handler) http.Handler { return h }
+
+	rt := &route{}
+	WithMiddleware(mw, mw)(rt)
+	WithMiddleware(mw)(rt)
+
+	as := Assert{t}
+	as.IntEqual(len(rt.middleware), 3, "WithMiddleware() got")
+}
+
+func TestServeMuxRoutes(t *testing.T) {
+	tag := func(s string) Middleware {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Tag", s)
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	mux := New()
+	mux.Get("/catalog/", TestHandler("all"), WithName("all"))
+	mux.Post("/catalog/", TestHandler("create"), WithMiddleware(tag("auth")))
+	mux.Get("host.com/catalog/:int", TestHandler("retrieve"))
+	mux.Get("/catalog/:int", TestHandler("v2"), WithMatchers(HeaderMatcher("X-API-Version", "2")))
+	mux.Get("/catalog/:int", TestHandler("v1"))
+
+	got := mux.Routes()
+	want := []Route{
+		{Method: http.MethodGet, Pattern: "/catalog/", Name: "all", Handler: TestHandler("all")},
+		{Method: http.MethodPost, Pattern: "/catalog/"},
+		{Method: http.MethodGet, Pattern: "/catalog/:int", Handler: TestHandler("v2")},
+		{Method: http.MethodGet, Pattern: "/catalog/:int", Handler: TestHandler("v1")},
+		{Method: http.MethodGet, Pattern: "host.com/catalog/:int", Handler: TestHandler("retrieve")},
+	}
+
+	as := Assert{t}
+	as.IntEqual(len(got), len(want), "ServeMux.Routes() length")
+
+	// cannot compare composed handler because functions can only compare with nil
+	w := httptest.NewRecorder()
+	got[1].Handler.ServeHTTP(w, nil)
+	got[1].Handler = nil
+
+	as.Equal(got, want, "ServeMux.Routes() got")
+	as.StrEqual(w.Header().Get("X-Tag"), "auth", "ServeMux.Routes() composed handler")
+}
+
+func TestServeMuxWriteTree(t *testing.T) {
+	mux := New()
+	mux.Get("/catalog/", TestHandler("all"))
+	mux.Post("/catalog/", TestHandler("create"))
+	mux.Get("/catalog/:int", TestHandler("retrieve"))
+	mux.Delete("/catalog/:int", TestHandler("delete"))
+	mux.Get("/users/:", TestHandler("user"))
+	mux.Get(":str.host.com/catalog", TestHandler("tenant"))
+
+	var b strings.Builder
+
+	as := Assert{t}
+	as.Equal(mux.WriteTree(&b), nil, "ServeMux.WriteTree() error")
+
+	want := `com
+  host
+    :str
+      /
+        catalog [GET]
+/
+  catalog
+    / [GET POST]
+    :int [DELETE GET]
+  users
+    :str [GET]
+`
+	as.StrEqual(b.String(), want, "ServeMux.WriteTree() got")
+}
@@ -0,0 +1,86 @@
+package mixer
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// SPA represents the single-page application fallback of the not matched requests.
+type SPA struct {
+	// Prefix is the path prefix of the application, e.g. `/app/`.
+	Prefix string
+
+	// Index is the handler of the index file, e.g. the one serving `dist/index.html`.
+	Index http.Handler
+
+	// Exclude is the path prefixes under Prefix which keep returning 404, e.g. `/app/api/`.
+	Exclude []string
+}
+
+// WithSPA adds the single-page application fallback: the not matched GET request
+// under s.Prefix is served by s.Index if it explicitly accepts text/html (the browser
+// navigation) and its path is not excluded and has no file extension (the asset).
+// Other not matched requests are handled by the error handler as usual. The path
+// registered for other methods is not matched by the fallback. If the prefixes of
+// several applications match the longest one is used.
+func WithSPA(s SPA) Option {
+	return func(mux *ServeMux) {
+		mux.spas = append(mux.spas, s)
+	}
+}
+
+// fallback returns the SPA index handler for the not matched request or nil otherwise.
+func (mux *ServeMux) fallback(r *http.Request, m Match, err error) http.Handler {
+	if len(mux.spas) == 0 || r.Method != http.MethodGet || len(m.Methods) != 0 || !acceptsHTML(r) {
+		return nil
+	}
+
+	if err, ok := err.(*ServeMuxError); !ok || err.err != ErrNotFound {
+		return nil
+	}
+
+	p := r.URL.Path
+	if path.Ext(path.Base(p)) != "" {
+		return nil
+	}
+
+	var found *SPA
+
+	for i, s := range mux.spas {
+		if strings.HasPrefix(p, s.Prefix) && (found == nil || len(s.Prefix) > len(found.Prefix)) {
+			found = &mux.spas[i]
+		}
+	}
+
+	if found == nil {
+		return nil
+	}
+
+	for _, prefix := range found.Exclude {
+		if strings.HasPrefix(p, prefix) {
+			return nil
+		}
+	}
+
+	return found.Index
+}
+
+// acceptsHTML reports whether the request explicitly accepts text/html,
+// the wildcards like `*/*` of the API clients are not enough.
+func acceptsHTML(r *http.Request) bool {
+	for _, rng := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, params, err := mime.ParseMediaType(rng)
+		if err != nil || mt != "text/html" {
+			continue
+		}
+
+		if q, err := strconv.ParseFloat(params["q"], 64); err != nil || q != 0 {
+			return true
+		}
+	}
+
+	return false
+}
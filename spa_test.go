@@ -0,0 +1,72 @@
+package mixer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServeMuxSPA(t *testing.T) {
+	index := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("index " + r.URL.Path))
+	})
+	admin := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("admin " + r.URL.Path))
+	})
+
+	mux := New(
+		WithSPA(SPA{Prefix: "/", Index: index, Exclude: []string{"/api/", "/assets/"}}),
+		WithSPA(SPA{Prefix: "/admin/", Index: admin}),
+	)
+	mux.Get("/api/users", TestHandler("users"))
+	mux.Post("/feedback", TestHandler("feedback"))
+
+	const html = "text/html,application/xhtml+xml,*/*;q=0.8"
+
+	cases := []struct {
+		name   string
+		method string
+		url    string
+		accept string
+		code   int
+		body   string
+	}{
+		{name: "route", method: http.MethodGet, url: "/api/users", accept: html, code: http.StatusOK},
+		{
+			name: "client route", method: http.MethodGet, url: "/users/42", accept: html,
+			code: http.StatusOK, body: "index /users/42",
+		},
+		{name: "root", method: http.MethodGet, url: "/", accept: html, code: http.StatusOK, body: "index /"},
+		{
+			name: "longest prefix", method: http.MethodGet, url: "/admin/x", accept: html,
+			code: http.StatusOK, body: "admin /admin/x",
+		},
+		{name: "api", method: http.MethodGet, url: "/api/missing", accept: html, code: http.StatusNotFound},
+		{name: "excluded assets", method: http.MethodGet, url: "/assets/logo", accept: html, code: http.StatusNotFound},
+		{name: "asset", method: http.MethodGet, url: "/app.js", accept: html, code: http.StatusNotFound},
+		{name: "any accept", method: http.MethodGet, url: "/users/42", accept: "*/*", code: http.StatusNotFound},
+		{name: "no accept", method: http.MethodGet, url: "/users/42", code: http.StatusNotFound},
+		{name: "html q=0", method: http.MethodGet, url: "/users/42", accept: "text/html;q=0", code: http.StatusNotFound},
+		{name: "post", method: http.MethodPost, url: "/users/42", accept: html, code: http.StatusNotFound},
+		{name: "other method path", method: http.MethodGet, url: "/feedback", accept: html, code: http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(c.method, c.url, nil)
+			if c.accept != "" {
+				r.Header.Set("Accept", c.accept)
+			}
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			as := Assert{t}
+			as.IntEqual(w.Code, c.code, "ServeMux.ServeHTTP() code")
+
+			if c.body != "" {
+				as.StrEqual(w.Body.String(), c.body, "ServeMux.ServeHTTP() body")
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package mixer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTimeout signals that the handler has not finished until the route deadline.
+	ErrTimeout = errors.New("handler timeout")
+
+	// ErrBodyTooLarge signals that the request body is larger than the route limit.
+	ErrBodyTooLarge = errors.New("request body too large")
+)
+
+type (
+	// timeoutWriter represents the buffered http.ResponseWriter of the handler with deadline.
+	timeoutWriter struct {
+		mu       sync.Mutex
+		header   http.Header
+		buf      bytes.Buffer
+		code     int
+		timedOut bool
+	}
+
+	// maxBytesReader represents the request body limited by http.MaxBytesReader
+	// which error of reading over the limit wraps ErrBodyTooLarge.
+	maxBytesReader struct {
+		io.ReadCloser
+		left int64
+		err  error
+	}
+)
+
+// WithTimeout sets the deadline of the route handler. The request context is
+// canceled after d and the response is served by the error handler with ErrTimeout
+// (503 by default, use WithErrorHandler for 504). Until the handler is finished
+// its response is buffered, so streaming is not supported.
+func WithTimeout(d time.Duration) RouteOption {
+	return func(rt *route) {
+		rt.timeout = d
+	}
+}
+
+// WithMaxBytes limits the request body size of the route. The request with larger
+// Content-Length is served by the error handler with ErrBodyTooLarge (413 by default),
+// otherwise the body is wrapped by http.MaxBytesReader. The body without Content-Length
+// (e.g. chunked) is not checked before the handler, so reading it over the limit
+// returns the error wrapping ErrBodyTooLarge and the handler replies on its own.
+func WithMaxBytes(n int64) RouteOption {
+	return func(rt *route) {
+		rt.maxBytes = n
+	}
+}
+
+// limitHandler returns h decorated by the timeout and the body size limit of rt.
+// If rt has no limits h is returned.
+func (mux *ServeMux) limitHandler(h http.Handler, rt *route) http.Handler {
+	if rt == nil || (rt.timeout <= 0 && rt.maxBytes <= 0) {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rt.maxBytes > 0 {
+			if r.ContentLength > rt.maxBytes {
+				mux.errorHandler(w, r, &ServeMuxError{r.Method, rt.pattern, ErrBodyTooLarge})
+				return
+			}
+
+			if r.Body != nil {
+				cp := *r
+				cp.Body = &maxBytesReader{
+					ReadCloser: http.MaxBytesReader(w, r.Body, rt.maxBytes),
+					left:       rt.maxBytes,
+					err:        &ServeMuxError{r.Method, rt.pattern, ErrBodyTooLarge},
+				}
+				r = &cp
+			}
+		}
+
+		if rt.timeout <= 0 {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		mux.serveTimeout(w, r, h, rt)
+	})
+}
+
+// serveTimeout serves r by h with the deadline of rt.
+// The panic of h is re-raised in the calling goroutine.
+func (mux *ServeMux) serveTimeout(w http.ResponseWriter, r *http.Request, h http.Handler, rt *route) {
+	ctx, cancel := context.WithTimeout(r.Context(), rt.timeout)
+	defer cancel()
+
+	r = r.WithContext(ctx)
+	tw := &timeoutWriter{header: make(http.Header)}
+	done := make(chan struct{})
+	panics := make(chan interface{}, 1)
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panics <- p
+			}
+		}()
+
+		h.ServeHTTP(tw, r)
+		close(done)
+	}()
+
+	select {
+	case p := <-panics:
+		panic(p)
+	case <-done:
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+
+		dst := w.Header()
+		for k, v := range tw.header {
+			dst[k] = v
+		}
+
+		if tw.code == 0 {
+			tw.code = http.StatusOK
+		}
+
+		w.WriteHeader(tw.code)
+		_, _ = w.Write(tw.buf.Bytes())
+	case <-ctx.Done():
+		tw.mu.Lock()
+		tw.timedOut = true
+		tw.mu.Unlock()
+
+		mux.errorHandler(w, r, &ServeMuxError{r.Method, rt.pattern, ErrTimeout})
+	}
+}
+
+// Header implements the http.ResponseWriter's Header.
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// Write implements the http.ResponseWriter's Write.
+// After the deadline it returns http.ErrHandlerTimeout.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+
+	return tw.buf.Write(b)
+}
+
+// WriteHeader implements the http.ResponseWriter's WriteHeader.
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+
+	tw.code = code
+}
+
+// Read implements the io.Reader's Read.
+func (b *maxBytesReader) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.left -= int64(n)
+
+	// http.MaxBytesReader fails only after the limit is read
+	if err != nil && err != io.EOF && b.left <= 0 {
+		err = b.err
+	}
+
+	return n, err
+}
@@ -0,0 +1,104 @@
+package mixer
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeMuxLimits(t *testing.T) {
+	mux := New(WithRecovery())
+	uploads := mux.Group("/uploads", WithMaxBytes(4), WithTimeout(time.Second))
+
+	uploads.HandleFunc(http.MethodPost, "/small", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ioutil.ReadAll(r.Body); err != nil {
+			code := http.StatusBadRequest
+			if errors.Is(err, ErrBodyTooLarge) {
+				code = http.StatusRequestEntityTooLarge
+			}
+
+			w.WriteHeader(code)
+
+			return
+		}
+
+		w.Header().Set("X-Done", "1")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("ok"))
+	})
+	uploads.HandleFunc(http.MethodPost, "/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		_, _ = w.Write([]byte("late"))
+	}, WithTimeout(10*time.Millisecond))
+	mux.PostFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}, WithTimeout(time.Second))
+
+	cases := []struct {
+		name   string
+		url    string
+		body   string
+		chunk  bool
+		code   int
+		header string
+		want   string
+	}{
+		{name: "ok", url: "/uploads/small", body: "abc", code: http.StatusCreated, header: "1", want: "ok"},
+		{
+			name: "too large",
+			url:  "/uploads/small",
+			body: "abcde",
+			code: http.StatusRequestEntityTooLarge,
+			want: "Request Entity Too Large\n",
+		},
+		{
+			name:  "too large chunked",
+			url:   "/uploads/small",
+			body:  "abcde",
+			chunk: true,
+			code:  http.StatusRequestEntityTooLarge,
+		},
+		{name: "timeout", url: "/uploads/slow", code: http.StatusServiceUnavailable, want: "Service Unavailable\n"},
+		{name: "panic", url: "/panic", code: http.StatusInternalServerError, want: "Internal Server Error\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := mustReq(http.NewRequest(http.MethodPost, c.url, strings.NewReader(c.body)))
+			if c.chunk {
+				req.ContentLength = -1
+			}
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			as := Assert{t}
+			as.IntEqual(w.Code, c.code, "ServeMux.ServeHTTP() code")
+			as.StrEqual(w.Header().Get("X-Done"), c.header, "ServeMux.ServeHTTP() header")
+			as.StrEqual(w.Body.String(), c.want, "ServeMux.ServeHTTP() body")
+		})
+	}
+}
+
+func TestTimeoutWriter(t *testing.T) {
+	tw := &timeoutWriter{header: make(http.Header)}
+	tw.WriteHeader(http.StatusAccepted)
+	tw.WriteHeader(http.StatusOK)
+
+	n, err := tw.Write([]byte("a"))
+
+	as := Assert{t}
+	as.IntEqual(tw.code, http.StatusAccepted, "first code")
+	as.IntEqual(n, 1, "written")
+	as.Equal(err, nil, "write error")
+
+	tw.timedOut = true
+	_, err = tw.Write([]byte("b"))
+
+	as.Equal(err, http.ErrHandlerTimeout, "timed out error")
+	as.StrEqual(tw.buf.String(), "a", "buffer")
+}